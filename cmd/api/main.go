@@ -47,6 +47,8 @@ func main() {
 		zlog.Logger.Fatal().Err(err).Msg("failed to build application")
 	}
 
+	appBuilder.StartBackground(ctx)
+
 	go func() {
 		if err := apiServer.Start(cfg.Server.Addr); err != nil && err != http.ErrServerClosed {
 			zlog.Logger.Fatal().Err(err).Msg("failed to start API server")
@@ -0,0 +1,103 @@
+// Command apikey issues, lists and revokes API keys directly in the database,
+// e.g. to create the first admin key:
+//
+//	apikey -name bootstrap -scopes admin
+//	apikey -name ci -scopes links:write -user dev@example.com -workspace 2
+//	apikey -list
+//	apikey -revoke 3
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+	"github.com/yokitheyo/URLShortener/internal/config"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/repository"
+)
+
+func main() {
+	zlog.Init()
+
+	configPath := flag.String("config", "config.yaml", "path to config.yaml")
+	name := flag.String("name", "", "name of the new key")
+	scopes := flag.String("scopes", "", "comma-separated scopes: links:write, analytics:read, admin")
+	userEmail := flag.String("user", "", "email of the user the key acts as, links it creates belong to them")
+	workspaceID := flag.Int64("workspace", 0, "id of the workspace the key is bound to, needs -user")
+	list := flag.Bool("list", false, "list keys")
+	revoke := flag.Int64("revoke", 0, "id of the key to revoke")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fail(err)
+	}
+
+	db, err := dbpg.New(cfg.Database.DSN, nil, &dbpg.Options{MaxOpenConns: 1})
+	if err != nil {
+		fail(fmt.Errorf("failed to connect to database: %w", err))
+	}
+	keys := usecase.NewAPIKeyService(repository.NewPostgresAPIKeyRepository(db))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch {
+	case *list:
+		all, err := keys.List(ctx)
+		if err != nil {
+			fail(err)
+		}
+		for _, k := range all {
+			state := "active"
+			if k.IsRevoked() {
+				state = "revoked"
+			}
+			fmt.Printf("%d\t%s\t%s...\t%s\tworkspace %d\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","), k.WorkspaceID, state)
+		}
+	case *revoke != 0:
+		if err := keys.Revoke(ctx, *revoke); err != nil {
+			fail(err)
+		}
+		fmt.Printf("key %d revoked\n", *revoke)
+	default:
+		var userID int64
+		if *userEmail != "" {
+			accounts := usecase.NewAccountService(repository.NewPostgresUserRepository(db),
+				repository.NewPostgresWorkspaceRepository(db), usecase.AccountOptions{})
+			user, err := accounts.FindUser(ctx, *userEmail)
+			if err != nil {
+				fail(err)
+			}
+			if user == nil {
+				fail(fmt.Errorf("no user with email %s", *userEmail))
+			}
+			userID = user.ID
+		}
+
+		issued, err := keys.Issue(ctx, dto.IssueAPIKeyCommand{
+			Name:        *name,
+			Scopes:      strings.Split(*scopes, ","),
+			UserID:      userID,
+			WorkspaceID: *workspaceID,
+		})
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("key %d (%s): %s\n", issued.ID, strings.Join(issued.Scopes, ","), issued.Key)
+		fmt.Println("store it now, it cannot be shown again")
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "apikey:", err)
+	os.Exit(1)
+}
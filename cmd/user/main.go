@@ -0,0 +1,63 @@
+// Command user creates accounts directly in the database. The password is
+// read from stdin so it does not end up in the shell history:
+//
+//	echo "$PASSWORD" | user -email admin@example.com -admin
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+	"github.com/yokitheyo/URLShortener/internal/config"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/repository"
+)
+
+func main() {
+	zlog.Init()
+
+	configPath := flag.String("config", "config.yaml", "path to config.yaml")
+	email := flag.String("email", "", "email of the new account")
+	admin := flag.Bool("admin", false, "create an admin account")
+	flag.Parse()
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		fail(fmt.Errorf("failed to read password from stdin: %w", err))
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fail(err)
+	}
+
+	db, err := dbpg.New(cfg.Database.DSN, nil, &dbpg.Options{MaxOpenConns: 1})
+	if err != nil {
+		fail(fmt.Errorf("failed to connect to database: %w", err))
+	}
+	accounts := usecase.NewAccountService(repository.NewPostgresUserRepository(db),
+		repository.NewPostgresWorkspaceRepository(db), usecase.AccountOptions{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user, err := accounts.Register(ctx, *email, password, *admin)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("user %d (%s) created, admin: %t\n", user.ID, user.Email, user.IsAdmin)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "user:", err)
+	os.Exit(1)
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
 	"github.com/yokitheyo/URLShortener/internal/presentation/handlers"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
 	"github.com/yokitheyo/URLShortener/internal/presentation/router"
 )
 
@@ -18,18 +20,28 @@ type API struct {
 	router  *router.Router
 }
 
-func NewAPI(handler *handlers.URLHandler) *API {
+// NewAPI serves the routes. Client IPs, which rate limits count, are read
+// from X-Forwarded-For only when the request comes from one of trustedProxies.
+func NewAPI(handler *handlers.URLHandler, auth *middleware.Auth, limits *middleware.RateLimiter, trustedProxies []string) (*API, error) {
 	engine := ginext.New("")
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 
 	api := &API{
 		Engine:  engine,
 		handler: handler,
 	}
 
-	api.router = router.NewRouter(engine, handler)
+	api.router = router.NewRouter(engine, handler, auth, limits)
 	api.router.Setup()
 
-	return api
+	return api, nil
+}
+
+// RouteWords lists the first path segments used by the API's routes
+func (api *API) RouteWords() []string {
+	return api.router.RouteWords()
 }
 
 func (api *API) Start(addr string) error {
@@ -1,25 +1,155 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
 
 // ShortenCommand - request to shorten URL
 type ShortenCommand struct {
-	URL     string
-	Custom  string
-	Expires int64 // unix timestamp
+	Actor      domain.Actor // becomes the owner of the link
+	Request    RequestMeta
+	URL        string
+	Custom     string
+	Expires    int64 // unix timestamp
+	Variants   []VariantSpec
+	StickyMode string
+
+	ForwardQuery    bool
+	PathPassthrough bool
+
+	UTM     UTMSpec
+	UTMMode string
+
+	RedirectCode int
+
+	FallbackURL string // used once the destination has been broken for long enough
+
+	ReuseExisting *bool // nil uses the server default
+
+	Signed bool // only reachable through a signed token, returned as Short
+
+	Domain string // host of a verified custom domain of the workspace, empty for the default domain
+}
+
+// UTMSpec - campaign tags to apply to the destination
+type UTMSpec struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Term     string
+	Content  string
+}
+
+// VariantSpec - weighted destination for an A/B split link
+type VariantSpec struct {
+	Label  string
+	URL    string
+	Weight int
 }
 
 // ShortenResult - result of URL shortening
 type ShortenResult struct {
 	Short     string
+	Domain    string // host of the link's custom domain, empty for the default domain
 	ExpiresAt time.Time
+	Reused    bool // an existing link to the same destination was returned
 }
 
 // RedirectCommand - request to resolve and redirect
 type RedirectCommand struct {
-	Short     string
+	Host          string // host the visitor came in on, it selects the domain
+	Short         string
+	Meta          ClickMetadata
+	VariantCookie string // variant label remembered by the visitor, if any
+	RawQuery      string // query string the visitor brought along
+	PathSuffix    string // wildcard part after /s/{short}/
+	SkipClick     bool   // resolve only, e.g. for HEAD requests
+	Proceed       bool   // visitor already confirmed the warning page
+}
+
+// RedirectResult - resolved destination for a redirect
+type RedirectResult struct {
+	URL             string
+	Variant         string
+	RememberVariant bool // visitor should keep Variant via cookie
+	StatusCode      int
+	ExpiresAt       time.Time
+	Cacheable       bool // same request always yields the same redirect
+	Interstitial    bool // show a warning page instead of redirecting
+	RiskFlag        string
+	ProceedTicket   string // the warning page posts it back to ConfirmWarning
+	Fallback        bool   // destination is broken, URL points at the fallback
+}
+
+// SetRiskFlagCommand - admin request to flag or unflag a link
+type SetRiskFlagCommand struct {
+	Actor   domain.Actor
+	Request RequestMeta
+	Short   string
+	Domain  string // host of the link's custom domain, empty for the default domain
+	Flag    string // empty clears the flag
+}
+
+// UpdateLinkCommand - request to edit, disable or re-enable a link, nil fields stay unchanged
+type UpdateLinkCommand struct {
+	Actor   domain.Actor
+	Request RequestMeta
+	Short   string
+	Domain  string // host of the link's custom domain, empty for the default domain
+
+	URL          *string
+	Expires      *int64 // unix timestamp
+	FallbackURL  *string
+	RedirectCode *int
+	Disabled     *bool
+}
+
+// DeleteLinkCommand - request to delete a link with its clicks
+type DeleteLinkCommand struct {
+	Actor   domain.Actor
+	Request RequestMeta
+	Short   string
+	Domain  string // host of the link's custom domain, empty for the default domain
+}
+
+// CreateWebhookCommand - request to subscribe a URL to link events
+type CreateWebhookCommand struct {
+	Actor  domain.Actor
+	URL    string
+	Events []string // empty subscribes to every event
+	Short  string   // limits the webhook to one link, empty for the whole workspace
+	Domain string   // host of that link's custom domain, empty for the default domain
+}
+
+// DeliveriesQuery - query for the delivery log of a webhook
+type DeliveriesQuery struct {
+	Actor     domain.Actor
+	WebhookID int64
+	Status    string // pending, delivered or dead, empty for all
+	Limit     int
+	Offset    int
+}
+
+// RequestMeta - where a request changing links came from, kept in the audit log
+type RequestMeta struct {
 	IP        string
-	UserAgent string
+	RequestID string
+}
+
+// AuditQuery - query for audit events, Short, WorkspaceID, UserID and Action filter when set
+type AuditQuery struct {
+	Actor       domain.Actor
+	Short       string
+	Domain      string // host of the link's custom domain, empty for the default domain
+	WorkspaceID int64
+	UserID      int64
+	Action      string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Offset      int
 }
 
 // ClickMetadata - metadata for recording a click
@@ -34,7 +164,9 @@ type ClickMetadata struct {
 
 // AnalyticsQuery - query for URL analytics
 type AnalyticsQuery struct {
-	Short string
+	Actor  domain.Actor
+	Short  string
+	Domain string // host of the link's custom domain, empty for the default domain
 }
 
 // AnalyticsResult - analytics for a URL
@@ -44,13 +176,19 @@ type AnalyticsResult struct {
 	CreatedAt  time.Time
 	ExpiresAt  time.Time
 	VisitCount int64
+
+	RiskFlag          string
+	WarningsShown     int64
+	WarningsProceeded int64
 }
 
 // DetailedAnalyticsQuery - query for detailed analytics
 type DetailedAnalyticsQuery struct {
-	Short string
-	From  time.Time
-	To    time.Time
+	Actor  domain.Actor
+	Short  string
+	Domain string // host of the link's custom domain, empty for the default domain
+	From   time.Time
+	To     time.Time
 }
 
 // DetailedAnalyticsResult - detailed analytics breakdown
@@ -60,12 +198,87 @@ type DetailedAnalyticsResult struct {
 	DeviceStats      map[string]int64
 	MobilePercentage float64
 	TotalClicks      int64
+	VariantStats     map[string]int64
+}
+
+// CampaignAnalyticsResult - links grouped by utm_campaign
+type CampaignAnalyticsResult struct {
+	Campaigns []CampaignSummary
+}
+
+// CampaignSummary - totals for one campaign
+type CampaignSummary struct {
+	Campaign string
+	Links    int64
+	Visits   int64
+	Shorts   []string
+}
+
+// UnhealthyLinksQuery - query for links whose destination keeps failing
+type UnhealthyLinksQuery struct {
+	Actor       domain.Actor
+	MinFailures int
+	Limit       int
+}
+
+// UnhealthyLinksResult - links with failing destinations
+type UnhealthyLinksResult struct {
+	Links []UnhealthyLink
+}
+
+// UnhealthyLink - health of one link's destination
+type UnhealthyLink struct {
+	Short       string
+	Original    string
+	FallbackURL string
+	Status      int
+	LatencyMs   int64
+	Failures    int
+	CheckedAt   time.Time
+	BrokenSince time.Time
+}
+
+// PreviewResult - public information about a link, shown before following it
+type PreviewResult struct {
+	Short      string
+	Original   string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	VisitCount int64
+}
+
+// ListLinksQuery - query for the links of the caller's workspace
+type ListLinksQuery struct {
+	Actor  domain.Actor
+	Mine   bool // only links the caller created
+	Limit  int
+	Offset int
+}
+
+// ListLinksResult - links of a workspace, newest first
+type ListLinksResult struct {
+	Links []LinkSummary
+}
+
+// LinkSummary - one link in a listing
+type LinkSummary struct {
+	Short     string
+	Original  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Visits    int64
+	OwnerID   int64
+	Disabled  bool
+	Signed    bool
+	Domain    string // host of the link's custom domain, empty for the default domain
 }
 
 // RecentClicksQuery - query for recent clicks
 type RecentClicksQuery struct {
-	Short string
-	Limit int
+	Actor  domain.Actor
+	Short  string
+	Domain string // host of the link's custom domain, empty for the default domain
+	Limit  int
 }
 
 // RecentClicksResult - recent clicks data
@@ -73,3 +286,29 @@ type RecentClicksResult struct {
 	Clicks []map[string]interface{}
 	Total  int
 }
+
+// IssueAPIKeyCommand - request to create an API key
+type IssueAPIKeyCommand struct {
+	Name   string
+	Scopes []string
+	UserID int64 // user the key acts as, 0 for none
+
+	WorkspaceID int64 // workspace the key is bound to, 0 to let requests pick one
+}
+
+// IssuedAPIKey - a new API key, Key is only available at this point
+type IssuedAPIKey struct {
+	ID        int64
+	Name      string
+	Key       string
+	Scopes    []string
+	CreatedAt time.Time
+}
+
+// SetMemberCommand - request to add a user to a workspace or change their role
+type SetMemberCommand struct {
+	Actor       domain.Actor
+	WorkspaceID int64
+	Email       string
+	Role        string
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// APIKeyRepository stores hashed API keys
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	FindByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id int64, at time.Time) (bool, error)
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
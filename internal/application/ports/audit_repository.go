@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// AuditRepository reads the audit log. Events are written by the repository
+// making the change, inside its transaction.
+type AuditRepository interface {
+	List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
+}
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+	"net"
+)
+
+// Blocklist reports hosts known to serve phishing or malware
+type Blocklist interface {
+	Contains(host string) bool
+}
+
+// HostResolver resolves host names, *net.Resolver satisfies it
+type HostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// RedirectResolver performs a single request to rawURL and returns the absolute
+// Location it redirects to, or an empty string if it does not redirect
+type RedirectResolver interface {
+	Resolve(ctx context.Context, rawURL string) (string, error)
+}
@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// CodeGenerator produces candidate short codes. grow is the number of times the
+// caller wants the code longer than usual, raised after repeated collisions.
+type CodeGenerator interface {
+	Generate(ctx context.Context, grow int) (string, error)
+}
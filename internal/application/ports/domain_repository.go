@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// DomainRepository stores the custom domains of workspaces. Lookups take the
+// workspace they are limited to, domain.AllWorkspaces lifts the limit. Hosts
+// are stored normalized with domain.NormalizeHost. Several workspaces may
+// claim a host, at most one of them verified it.
+type DomainRepository interface {
+	Create(ctx context.Context, d *domain.CustomDomain) error
+	FindByID(ctx context.Context, workspaceID, id int64) (*domain.CustomDomain, error)
+	FindByHost(ctx context.Context, workspaceID int64, host string) (*domain.CustomDomain, error)
+	List(ctx context.Context, workspaceID int64) ([]*domain.CustomDomain, error)
+	MarkVerified(ctx context.Context, id int64, at time.Time) error
+	Delete(ctx context.Context, workspaceID, id int64) (bool, error)
+}
+
+// DomainVerifier fetches what a host serves at domain.DomainVerificationPath
+type DomainVerifier interface {
+	FetchToken(ctx context.Context, host string) (string, error)
+}
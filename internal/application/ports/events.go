@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// OutboxRepository reads the domain events that link changes wrote to the
+// outbox, so the relay can publish them
+type OutboxRepository interface {
+	// ClaimPending returns unpublished events oldest first and hides them
+	// from other relays for lease, after which they are claimed again
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.DomainEvent, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EventSink publishes domain events to a consumer outside the service, such as
+// a message bus. A batch is published in order and either fully or with an
+// error, after which the whole batch is published again.
+type EventSink interface {
+	Publish(ctx context.Context, events []*domain.DomainEvent) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// HealthProber requests a destination and reports how it answered
+type HealthProber interface {
+	Probe(ctx context.Context, rawURL string) (status int, latency time.Duration, err error)
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit allows Requests per Window to every key
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateDecision is the outcome of counting one request against a RateLimit
+type RateDecision struct {
+	Allowed    bool
+	Remaining  int           // requests left in the current window
+	Reset      time.Duration // until the budget is fully available again
+	RetryAfter time.Duration // until the next request is allowed, 0 if it already is
+}
+
+// RateLimiter counts requests per key, e.g. per API key or client IP
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateDecision, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// ReservedWordRepository stores short codes reserved by admins
+type ReservedWordRepository interface {
+	List(ctx context.Context) ([]domain.ReservedWord, error)
+	Contains(ctx context.Context, word string) (bool, error)
+	Add(ctx context.Context, word string) (bool, error)
+	Remove(ctx context.Context, word string) (bool, error)
+}
@@ -7,13 +7,32 @@ import (
 	"github.com/yokitheyo/URLShortener/internal/domain"
 )
 
-// URLRepository defines domain.URL persistence operations
+// URLRepository defines domain.URL persistence operations.
+// Lookups take the workspace they are limited to, domain.AllWorkspaces lifts
+// the limit, and codes are looked up on a domain, domain.DefaultDomain being
+// the service's own host. Methods taking a link id act on a link that was already looked up.
+// Create, Update and Delete store their audit event in the same transaction.
 type URLRepository interface {
-	Create(ctx context.Context, url *domain.URL) error
-	FindByShort(ctx context.Context, short string) (*domain.URL, error)
+	Create(ctx context.Context, url *domain.URL, event *domain.AuditEvent) error
+	FindByShort(ctx context.Context, workspaceID, domainID int64, short string) (*domain.URL, error)
 	IncrementVisits(ctx context.Context, id int64) error
 	SaveClick(ctx context.Context, click *domain.Click) error
-	AggregateByDay(ctx context.Context, short string, from, to time.Time) (map[string]int64, error)
-	GetDeviceStats(ctx context.Context, short string, from, to time.Time) (map[string]int64, error)
-	GetRecentClicks(ctx context.Context, short string, limit int) ([]*domain.Click, error)
+	AggregateByDay(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error)
+	GetDeviceStats(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error)
+	GetRecentClicks(ctx context.Context, urlID int64, limit int) ([]*domain.Click, error)
+	GetVariants(ctx context.Context, urlID int64) ([]domain.Variant, error)
+	GetVariantStats(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error)
+	GetCampaignStats(ctx context.Context, workspaceID int64) ([]domain.CampaignStats, error)
+	Update(ctx context.Context, url *domain.URL, event *domain.AuditEvent) (bool, error)
+	Delete(ctx context.Context, id int64, event *domain.AuditEvent) (bool, error)
+	IncrementWarningsShown(ctx context.Context, id int64) error
+	IncrementWarningsProceeded(ctx context.Context, id int64) error
+	// ListForHealthCheck returns due links round-robin over their hosts, least
+	// recently checked first, so a host with many links cannot fill a batch
+	ListForHealthCheck(ctx context.Context, workspaceID int64, checkedBefore time.Time, limit int) ([]*domain.URL, error)
+	UpdateHealth(ctx context.Context, id int64, status int, latencyMs int64, healthy bool, checkedAt time.Time) error
+	ListUnhealthy(ctx context.Context, workspaceID int64, minFailures, limit int) ([]*domain.URL, error)
+	FindReusable(ctx context.Context, workspaceID, ownerID, domainID int64, hash string, minExpiresAt time.Time) (*domain.URL, error)
+	ListLinks(ctx context.Context, workspaceID, ownerID int64, limit, offset int) ([]*domain.URL, error)
+	ClaimExpired(ctx context.Context, limit int) ([]*domain.URL, error)
 }
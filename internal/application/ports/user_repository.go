@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// UserRepository stores accounts and their web UI sessions
+type UserRepository interface {
+	Create(ctx context.Context, user *domain.User) error
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+	FindByID(ctx context.Context, id int64) (*domain.User, error)
+	CreateSession(ctx context.Context, session *domain.Session) error
+	FindSession(ctx context.Context, tokenHash string, now time.Time) (*domain.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+}
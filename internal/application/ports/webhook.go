@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// WebhookRepository stores webhook subscriptions and the outbox of their deliveries.
+// Lookups take the workspace they are limited to, domain.AllWorkspaces lifts the limit.
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *domain.Webhook) error
+	FindByID(ctx context.Context, workspaceID, id int64) (*domain.Webhook, error)
+	List(ctx context.Context, workspaceID int64) ([]*domain.Webhook, error)
+	Delete(ctx context.Context, workspaceID, id int64) (bool, error)
+
+	// Enqueue stores a delivery for every webhook of the workspace subscribed
+	// to event on linkID and returns how many were stored
+	Enqueue(ctx context.Context, workspaceID, linkID int64, event string, payload json.RawMessage) (int, error)
+	// ClaimDue returns pending deliveries that are due and moves them lease
+	// into the future, so other dispatchers skip them while they are sent
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, status int) error
+	MarkFailed(ctx context.Context, id int64, status int, reason string, nextAttemptAt time.Time, dead bool) error
+	ListDeliveries(ctx context.Context, webhookID int64, status string, limit, offset int) ([]*domain.WebhookDelivery, error)
+}
+
+// WebhookSender posts a delivery to its receiver, signed with secret, and
+// returns the HTTP status it answered with
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, delivery *domain.WebhookDelivery) (int, error)
+}
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// WorkspaceRepository stores workspaces and the roles of their members
+type WorkspaceRepository interface {
+	Create(ctx context.Context, ws *domain.Workspace, ownerID int64) error
+	FindByID(ctx context.Context, id int64) (*domain.Workspace, error)
+	ListForUser(ctx context.Context, userID int64) ([]domain.Membership, error)
+	FindMember(ctx context.Context, workspaceID, userID int64) (*domain.Member, error)
+	FirstMembership(ctx context.Context, userID int64) (*domain.Member, error)
+	ListMembers(ctx context.Context, workspaceID int64) ([]domain.Member, error)
+	SetMember(ctx context.Context, workspaceID, userID int64, role string) error
+	RemoveMember(ctx context.Context, workspaceID, userID int64) (bool, error)
+	CountOwners(ctx context.Context, workspaceID int64) (int, error)
+}
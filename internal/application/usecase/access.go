@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// requireAccess allows members of u's workspace and admins
+func requireAccess(actor domain.Actor, u *domain.URL) error {
+	if actor.CanAccess(u) {
+		return nil
+	}
+	return denied(actor)
+}
+
+// requireWriter allows admins and owners and editors of the actor's workspace
+func requireWriter(actor domain.Actor) error {
+	if actor.Admin || actor.CanWrite() {
+		return nil
+	}
+	if actor.IsAnonymous() {
+		return ErrUnauthorized
+	}
+	return fmt.Errorf("%w: changing links needs the %s or %s role in a workspace",
+		ErrForbidden, domain.RoleOwner, domain.RoleEditor)
+}
+
+func requireAdmin(actor domain.Actor) error {
+	if actor.Admin {
+		return nil
+	}
+	return denied(actor)
+}
+
+// workspaceScope returns the workspace the actor's queries are limited to.
+// Admins who did not pick a workspace see all of them, other callers must
+// work in a workspace they belong to.
+func workspaceScope(actor domain.Actor) (int64, error) {
+	if actor.Admin {
+		return actor.WorkspaceID, nil
+	}
+	if !actor.IsMember() {
+		return 0, denied(actor)
+	}
+	return actor.WorkspaceID, nil
+}
+
+func denied(actor domain.Actor) error {
+	if actor.IsAnonymous() {
+		return ErrUnauthorized
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything longer
+	maxEmailLength    = 254
+
+	sessionTokenBytes     = 32
+	defaultSessionTTL     = 7 * 24 * time.Hour
+	sessionCleanupTimeout = 5 * time.Second
+)
+
+// dummyPasswordHash is compared against for unknown emails, so a failed
+// login takes as long whether or not the account exists
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("no account has this password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// AccountOptions configure AccountService
+type AccountOptions struct {
+	SessionTTL        time.Duration // 0 means a week
+	AllowRegistration bool          // anyone may sign up through SignUp
+}
+
+// AccountService registers users and manages their web UI sessions.
+// Session tokens are random and stored as sha256 hashes, like API keys.
+type AccountService struct {
+	users      ports.UserRepository
+	workspaces ports.WorkspaceRepository
+	opts       AccountOptions
+}
+
+func NewAccountService(users ports.UserRepository, workspaces ports.WorkspaceRepository, opts AccountOptions) *AccountService {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = defaultSessionTTL
+	}
+	return &AccountService{users: users, workspaces: workspaces, opts: opts}
+}
+
+// SignUp registers a regular account if self-registration is open
+func (s *AccountService) SignUp(ctx context.Context, email, password string) (*domain.User, error) {
+	if !s.opts.AllowRegistration {
+		return nil, fmt.Errorf("%w: registration is closed", ErrForbidden)
+	}
+	return s.Register(ctx, email, password, false)
+}
+
+// Register creates an account without checking AllowRegistration, for the command line.
+// Every account starts with a personal workspace it owns.
+func (s *AccountService) Register(ctx context.Context, email, password string, admin bool) (*domain.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return nil, fmt.Errorf("%w: password must be %d to %d characters", ErrInvalidAccount, minPasswordLength, maxPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.User{
+		Email:        email,
+		PasswordHash: string(hash),
+		IsAdmin:      admin,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		if errors.Is(err, domain.ErrEmailTaken) {
+			return nil, ErrEmailTaken
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to create user")
+		return nil, fmt.Errorf("failed to create user")
+	}
+
+	// the account exists at this point, a missing workspace can be created later
+	ws := &domain.Workspace{Name: user.Email, CreatedAt: user.CreatedAt}
+	if err := s.workspaces.Create(ctx, ws, user.ID); err != nil {
+		zlog.Logger.Error().Err(err).Int64("user_id", user.ID).Msg("failed to create personal workspace")
+	}
+	return user, nil
+}
+
+// Login checks the password and opens a session, the returned token goes into the session cookie
+func (s *AccountService) Login(ctx context.Context, email, password string) (*domain.User, string, time.Time, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, "", time.Time{}, ErrInvalidCredentials
+	}
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up user")
+		return nil, "", time.Time{}, fmt.Errorf("database error")
+	}
+	if user == nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return nil, "", time.Time{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, "", time.Time{}, ErrInvalidCredentials
+	}
+
+	raw := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("failed to generate session: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	session := &domain.Session{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.opts.SessionTTL),
+	}
+	if err := s.users.CreateSession(ctx, session); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to save session")
+		return nil, "", time.Time{}, fmt.Errorf("failed to save session")
+	}
+
+	s.purgeExpiredSessions(now)
+	return user, token, session.ExpiresAt, nil
+}
+
+// Logout ends the session, unknown tokens are ignored
+func (s *AccountService) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if err := s.users.DeleteSession(ctx, hashToken(token)); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to delete session")
+		return fmt.Errorf("failed to delete session")
+	}
+	return nil
+}
+
+// AuthenticateSession returns the user of an active session or ErrUnauthorized
+func (s *AccountService) AuthenticateSession(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, ErrUnauthorized
+	}
+
+	session, err := s.users.FindSession(ctx, hashToken(token), time.Now())
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up session")
+		return nil, fmt.Errorf("database error")
+	}
+	if session == nil {
+		return nil, ErrUnauthorized
+	}
+
+	user, err := s.users.FindByID(ctx, session.UserID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up user")
+		return nil, fmt.Errorf("database error")
+	}
+	if user == nil {
+		return nil, ErrUnauthorized
+	}
+	return user, nil
+}
+
+// FindUser looks an account up by email, nil if there is none
+func (s *AccountService) FindUser(ctx context.Context, email string) (*domain.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	return s.users.FindByEmail(ctx, email)
+}
+
+// purgeExpiredSessions runs on login so the sessions table does not grow forever
+func (s *AccountService) purgeExpiredSessions(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionCleanupTimeout)
+	defer cancel()
+
+	if _, err := s.users.DeleteExpiredSessions(ctx, now); err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to delete expired sessions")
+	}
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > maxEmailLength {
+		return "", fmt.Errorf("%w: invalid email", ErrInvalidAccount)
+	}
+	return email, nil
+}
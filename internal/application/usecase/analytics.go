@@ -6,20 +6,25 @@ import (
 
 	"github.com/wb-go/wbf/zlog"
 	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
 )
 
 func (uc *URLShortenerUseCase) GetAnalytics(ctx context.Context, query dto.AnalyticsQuery) (dto.AnalyticsResult, error) {
-	if query.Short == "" || len(query.Short) > 50 {
+	query.Short = domain.NormalizeShortCode(query.Short)
+	if !domain.IsShortCodeLength(query.Short) {
 		return dto.AnalyticsResult{}, ErrInvalidQuery
 	}
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return dto.AnalyticsResult{}, err
+	}
 
-	u, err := uc.repo.FindByShort(ctx, query.Short)
+	u, err := uc.findManaged(ctx, scope, query.Domain, query.Short)
 	if err != nil {
-		zlog.Logger.Warn().Err(err).Msg("repo error in analytics")
-		return dto.AnalyticsResult{}, ErrNotFound
+		return dto.AnalyticsResult{}, err
 	}
-	if u == nil {
-		return dto.AnalyticsResult{}, ErrNotFound
+	if err := requireAccess(query.Actor, u); err != nil {
+		return dto.AnalyticsResult{}, err
 	}
 
 	return dto.AnalyticsResult{
@@ -28,31 +33,49 @@ func (uc *URLShortenerUseCase) GetAnalytics(ctx context.Context, query dto.Analy
 		CreatedAt:  u.CreatedAt,
 		ExpiresAt:  u.ExpiresAt,
 		VisitCount: u.Visits,
+
+		RiskFlag:          u.RiskFlag,
+		WarningsShown:     u.WarningsShown,
+		WarningsProceeded: u.WarningsProceeded,
 	}, nil
 }
 
 func (uc *URLShortenerUseCase) GetDetailedAnalytics(ctx context.Context, query dto.DetailedAnalyticsQuery) (dto.DetailedAnalyticsResult, error) {
+	query.Short = domain.NormalizeShortCode(query.Short)
 	if query.Short == "" {
 		return dto.DetailedAnalyticsResult{}, fmt.Errorf("short code is required")
 	}
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return dto.DetailedAnalyticsResult{}, err
+	}
 
-	u, err := uc.repo.FindByShort(ctx, query.Short)
-	if err != nil || u == nil {
-		return dto.DetailedAnalyticsResult{}, fmt.Errorf("not found")
+	u, err := uc.findManaged(ctx, scope, query.Domain, query.Short)
+	if err != nil {
+		return dto.DetailedAnalyticsResult{}, err
+	}
+	if err := requireAccess(query.Actor, u); err != nil {
+		return dto.DetailedAnalyticsResult{}, err
 	}
 
-	dailyClicks, err := uc.repo.AggregateByDay(ctx, query.Short, query.From, query.To)
+	dailyClicks, err := uc.repo.AggregateByDay(ctx, u.ID, query.From, query.To)
 	if err != nil {
 		zlog.Logger.Warn().Err(err).Msg("failed to get daily stats")
 		dailyClicks = make(map[string]int64)
 	}
 
-	deviceStats, err := uc.repo.GetDeviceStats(ctx, query.Short, query.From, query.To)
+	deviceStats, err := uc.repo.GetDeviceStats(ctx, u.ID, query.From, query.To)
 	if err != nil {
 		zlog.Logger.Warn().Err(err).Msg("failed to get device stats")
 		deviceStats = make(map[string]int64)
 	}
 
+	variantStats, err := uc.repo.GetVariantStats(ctx, u.ID, query.From, query.To)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to get variant stats")
+		variantStats = make(map[string]int64)
+	}
+
 	mobilePercentage := 0.0
 	if u.Visits > 0 && deviceStats["mobile"] > 0 {
 		mobilePercentage = float64(deviceStats["mobile"]) / float64(u.Visits) * 100
@@ -64,5 +87,69 @@ func (uc *URLShortenerUseCase) GetDetailedAnalytics(ctx context.Context, query d
 		DeviceStats:      deviceStats,
 		MobilePercentage: mobilePercentage,
 		TotalClicks:      u.Visits,
+		VariantStats:     variantStats,
+	}, nil
+}
+
+// GetCampaignAnalytics groups the links of the actor's workspace by campaign
+func (uc *URLShortenerUseCase) GetCampaignAnalytics(ctx context.Context, actor domain.Actor) (dto.CampaignAnalyticsResult, error) {
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return dto.CampaignAnalyticsResult{}, err
+	}
+
+	stats, err := uc.repo.GetCampaignStats(ctx, scope)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to get campaign stats")
+		return dto.CampaignAnalyticsResult{}, fmt.Errorf("failed to get campaign stats")
+	}
+
+	campaigns := make([]dto.CampaignSummary, len(stats))
+	for i, st := range stats {
+		campaigns[i] = dto.CampaignSummary{
+			Campaign: st.Campaign,
+			Links:    st.Links,
+			Visits:   st.Visits,
+			Shorts:   st.Shorts,
+		}
+	}
+
+	return dto.CampaignAnalyticsResult{Campaigns: campaigns}, nil
+}
+
+// Preview returns what anyone may see about a link on host before following
+// it: its destination, dates and click count
+func (uc *URLShortenerUseCase) Preview(ctx context.Context, host, short string) (dto.PreviewResult, error) {
+	short = domain.NormalizeShortCode(short)
+	code, signed, err := uc.openShort(short)
+	if err != nil {
+		return dto.PreviewResult{}, err
+	}
+	if !domain.IsShortCodeLength(code) {
+		return dto.PreviewResult{}, ErrNotFound
+	}
+
+	domainID, err := uc.visitorDomain(ctx, host)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("repo error finding domain")
+		return dto.PreviewResult{}, ErrNotFound
+	}
+
+	u, err := uc.repo.FindByShort(ctx, domain.AllWorkspaces, domainID, code)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("repo error in preview")
+		return dto.PreviewResult{}, ErrNotFound
+	}
+	if !reachable(u, signed) {
+		return dto.PreviewResult{}, ErrNotFound
+	}
+
+	// a signed link is shown under its token, its code alone leads nowhere
+	return dto.PreviewResult{
+		Short:      short,
+		Original:   u.Original,
+		CreatedAt:  u.CreatedAt,
+		ExpiresAt:  u.ExpiresAt,
+		VisitCount: u.Visits,
 	}, nil
 }
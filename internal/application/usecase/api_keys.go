@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	apiKeyPrefix    = "usk_"
+	apiKeyBytes     = 24
+	apiKeyShownLen  = len(apiKeyPrefix) + 6
+	apiKeyNameLimit = 100
+
+	// last_used_at is only written this often per key, not on every request
+	apiKeyTouchEvery = time.Minute
+)
+
+// APIKeyService issues API keys and authenticates requests made with them.
+// Keys look like usk_<32 url-safe characters> and are stored as sha256 hashes.
+type APIKeyService struct {
+	repo ports.APIKeyRepository
+}
+
+func NewAPIKeyService(repo ports.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Issue creates a key and returns it in plain text, the only time it is available
+func (s *APIKeyService) Issue(ctx context.Context, cmd dto.IssueAPIKeyCommand) (dto.IssuedAPIKey, error) {
+	name := strings.TrimSpace(cmd.Name)
+	if name == "" || len(name) > apiKeyNameLimit {
+		return dto.IssuedAPIKey{}, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidAPIKey, apiKeyNameLimit)
+	}
+
+	var scopes []string
+	for _, scope := range cmd.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !domain.IsValidScope(scope) {
+			return dto.IssuedAPIKey{}, fmt.Errorf("%w: unknown scope %q, use %s",
+				ErrInvalidAPIKey, scope, strings.Join(domain.APIKeyScopes, ", "))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return dto.IssuedAPIKey{}, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	// without a user a key has no workspace and would act as nobody, only admins need none
+	if cmd.UserID == 0 && !slices.Contains(scopes, domain.ScopeAdmin) {
+		return dto.IssuedAPIKey{}, fmt.Errorf("%w: a key without the admin scope needs a user", ErrInvalidAPIKey)
+	}
+	// the key gets its role from the user's membership
+	if cmd.WorkspaceID != 0 && cmd.UserID == 0 {
+		return dto.IssuedAPIKey{}, fmt.Errorf("%w: a key bound to a workspace needs a user", ErrInvalidAPIKey)
+	}
+
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return dto.IssuedAPIKey{}, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	token := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key := &domain.APIKey{
+		Name:        name,
+		Prefix:      token[:apiKeyShownLen],
+		Hash:        hashToken(token),
+		Scopes:      scopes,
+		UserID:      cmd.UserID,
+		WorkspaceID: cmd.WorkspaceID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to save API key")
+		return dto.IssuedAPIKey{}, fmt.Errorf("failed to save API key")
+	}
+
+	return dto.IssuedAPIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		Key:       token,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
+
+// Authenticate returns the active key for token or ErrUnauthorized
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return nil, ErrUnauthorized
+	}
+
+	key, err := s.repo.FindByHash(ctx, hashToken(token))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up API key")
+		return nil, fmt.Errorf("database error")
+	}
+	if key == nil || key.IsRevoked() {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now()
+	if now.Sub(key.LastUsedAt) >= apiKeyTouchEvery {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			zlog.Logger.Warn().Err(err).Int64("key_id", key.ID).Msg("failed to update API key last use")
+		}
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list API keys")
+		return nil, fmt.Errorf("failed to list API keys")
+	}
+	return keys, nil
+}
+
+// Revoke disables a key, requests made with it are rejected from then on
+func (s *APIKeyService) Revoke(ctx context.Context, id int64) error {
+	revoked, err := s.repo.Revoke(ctx, id, time.Now())
+	if err != nil {
+		zlog.Logger.Error().Err(err).Int64("key_id", id).Msg("failed to revoke API key")
+		return fmt.Errorf("failed to revoke API key")
+	}
+	if !revoked {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditService reads the audit log of link changes. The events themselves are
+// written by the use cases making the changes.
+type AuditService struct {
+	events  ports.AuditRepository
+	domains ports.DomainRepository
+}
+
+func NewAuditService(events ports.AuditRepository, domains ports.DomainRepository) *AuditService {
+	return &AuditService{events: events, domains: domains}
+}
+
+// LinkHistory returns the events of one link to members of its workspace,
+// newest first. The history of deleted links stays readable.
+func (s *AuditService) LinkHistory(ctx context.Context, query dto.AuditQuery) ([]*domain.AuditEvent, error) {
+	short := domain.NormalizeShortCode(query.Short)
+	if short == "" {
+		return nil, ErrShortCodeRequired
+	}
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return nil, err
+	}
+
+	domainID, err := linkDomainID(ctx, s.domains, scope, query.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, domain.AuditFilter{
+		WorkspaceID: scope,
+		Short:       short,
+		DomainID:    &domainID,
+		Limit:       query.Limit,
+		Offset:      query.Offset,
+	})
+}
+
+// Search lets admins query the whole audit log
+func (s *AuditService) Search(ctx context.Context, query dto.AuditQuery) ([]*domain.AuditEvent, error) {
+	if err := requireAdmin(query.Actor); err != nil {
+		return nil, err
+	}
+	if query.Action != "" && !slices.Contains(domain.AuditActions, query.Action) {
+		return nil, fmt.Errorf("%w: action must be one of %s", ErrInvalidQuery, strings.Join(domain.AuditActions, ", "))
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: to is before from", ErrInvalidQuery)
+	}
+
+	return s.list(ctx, domain.AuditFilter{
+		WorkspaceID: query.WorkspaceID,
+		Short:       domain.NormalizeShortCode(query.Short),
+		ActorUserID: query.UserID,
+		Action:      query.Action,
+		From:        query.From,
+		To:          query.To,
+		Limit:       query.Limit,
+		Offset:      query.Offset,
+	})
+}
+
+func (s *AuditService) list(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	events, err := s.events.List(ctx, filter)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to read audit log")
+		return nil, fmt.Errorf("failed to read audit log")
+	}
+	return events, nil
+}
+
+// linkSnapshot is what audit events keep of a link before and after a change
+type linkSnapshot struct {
+	Original     string `json:"original"`
+	ExpiresAt    int64  `json:"expires_at"`
+	RedirectCode int    `json:"redirect_code,omitempty"`
+	FallbackURL  string `json:"fallback_url,omitempty"`
+	RiskFlag     string `json:"risk_flag,omitempty"`
+	Disabled     bool   `json:"disabled"`
+	OwnerID      int64  `json:"owner_id,omitempty"`
+}
+
+func snapshot(u *domain.URL) json.RawMessage {
+	if u == nil {
+		return nil
+	}
+	doc, err := json.Marshal(linkSnapshot{
+		Original:     u.Original,
+		ExpiresAt:    u.ExpiresAt.Unix(),
+		RedirectCode: u.RedirectCode,
+		FallbackURL:  u.FallbackURL,
+		RiskFlag:     u.RiskFlag,
+		Disabled:     u.IsDisabled,
+		OwnerID:      u.OwnerID,
+	})
+	if err != nil {
+		return nil
+	}
+	return doc
+}
+
+// newAuditEvent describes a change by actor from before to after, either of
+// which is nil when the link is created or deleted
+func newAuditEvent(action string, actor domain.Actor, req dto.RequestMeta, before, after *domain.URL) *domain.AuditEvent {
+	link := after
+	if link == nil {
+		link = before
+	}
+	return &domain.AuditEvent{
+		Action:      action,
+		LinkID:      link.ID,
+		Short:       link.Short,
+		DomainID:    link.DomainID,
+		WorkspaceID: link.WorkspaceID,
+		ActorUserID: actor.UserID,
+		ActorKeyID:  actor.KeyID,
+		IP:          req.IP,
+		RequestID:   req.RequestID,
+		Before:      snapshot(before),
+		After:       snapshot(after),
+		CreatedAt:   time.Now(),
+	}
+}
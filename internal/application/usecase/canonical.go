@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// defaultTrackingParams are dropped from canonical URLs when stripping is enabled.
+// Entries ending with "*" match by prefix.
+var defaultTrackingParams = []string{
+	"utm_*", "gclid", "dclid", "fbclid", "msclkid", "yclid", "igshid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "_hsenc", "_hsmi", "ref_src",
+}
+
+// CanonicalOptions configure Canonicalizer
+type CanonicalOptions struct {
+	SortQuery      bool     // order query parameters by key
+	StripTracking  bool     // drop TrackingParams from the query
+	TrackingParams []string // built-in list if empty
+}
+
+// Canonicalizer turns a parsed destination into the form used to compare links.
+// It complements canonicalizeURL (scheme, host, punycode, default port) with
+// path cleaning and optional query normalization. The raw destination is still
+// what visitors are redirected to.
+type Canonicalizer struct {
+	opts     CanonicalOptions
+	exact    map[string]struct{}
+	prefixes []string
+}
+
+func NewCanonicalizer(opts CanonicalOptions) *Canonicalizer {
+	if len(opts.TrackingParams) == 0 {
+		opts.TrackingParams = defaultTrackingParams
+	}
+
+	c := &Canonicalizer{
+		opts:  opts,
+		exact: make(map[string]struct{}, len(opts.TrackingParams)),
+	}
+	for _, p := range opts.TrackingParams {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+		case strings.HasSuffix(p, "*"):
+			c.prefixes = append(c.prefixes, strings.TrimSuffix(p, "*"))
+		default:
+			c.exact[p] = struct{}{}
+		}
+	}
+	return c
+}
+
+// Canonicalize returns the canonical string for u, which must come from
+// DestinationPolicy.Parse. u itself is not modified.
+func (c *Canonicalizer) Canonicalize(u *url.URL) string {
+	out := *u
+	out.ForceQuery = false
+	cleanURLPath(&out)
+
+	if c.opts.StripTracking {
+		out.RawQuery = c.stripTracking(out.RawQuery)
+	}
+	if c.opts.SortQuery && out.RawQuery != "" {
+		// Encode sorts by key and keeps the order of repeated keys
+		if query, err := url.ParseQuery(out.RawQuery); err == nil {
+			out.RawQuery = query.Encode()
+		}
+	}
+
+	return out.String()
+}
+
+func (c *Canonicalizer) isTracking(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := c.exact[key]; ok {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripTracking drops tracking parameters while keeping the order and encoding of the rest
+func (c *Canonicalizer) stripTracking(rawQuery string) string {
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && c.isTracking(name) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	return strings.Join(kept, "&")
+}
+
+// cleanURLPath resolves "." and ".." segments and duplicate slashes, keeps a
+// trailing slash and turns an empty path into "/"
+func cleanURLPath(u *url.URL) {
+	escaped := u.EscapedPath()
+	if escaped == "" {
+		u.Path, u.RawPath = "/", ""
+		return
+	}
+
+	cleaned := path.Clean("/" + escaped)
+	if strings.HasSuffix(escaped, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+
+	unescaped, err := url.PathUnescape(cleaned)
+	if err != nil {
+		return
+	}
+	u.Path, u.RawPath = unescaped, cleaned
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	plain := CanonicalOptions{}
+	full := CanonicalOptions{SortQuery: true, StripTracking: true}
+
+	tests := []struct {
+		name string
+		opts CanonicalOptions
+		raw  string
+		want string
+	}{
+		{name: "scheme and host lowercased", opts: plain, raw: "HTTPS://Example.COM/Path", want: "https://example.com/Path"},
+		{name: "path case kept", opts: plain, raw: "https://example.com/CaseSensitive", want: "https://example.com/CaseSensitive"},
+		{name: "trailing dot on host", opts: plain, raw: "https://example.com./a", want: "https://example.com/a"},
+		{name: "default https port", opts: plain, raw: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "default http port", opts: plain, raw: "http://example.com:80/a", want: "http://example.com/a"},
+		{name: "other port kept", opts: plain, raw: "https://example.com:80/a", want: "https://example.com:80/a"},
+		{name: "ipv6 default port", opts: plain, raw: "https://[2001:DB8::1]:443/a", want: "https://[2001:db8::1]/a"},
+		{name: "punycode host", opts: plain, raw: "https://bücher.example/a", want: "https://xn--bcher-kva.example/a"},
+		{name: "empty path", opts: plain, raw: "https://example.com", want: "https://example.com/"},
+		{name: "trailing slash kept", opts: plain, raw: "https://example.com/docs/", want: "https://example.com/docs/"},
+		{name: "dot segments and double slashes", opts: plain, raw: "https://example.com/a//b/./c/../d", want: "https://example.com/a/b/d"},
+		{name: "escaped path kept", opts: plain, raw: "https://example.com/a%2Fb", want: "https://example.com/a%2Fb"},
+		{name: "empty query dropped", opts: plain, raw: "https://example.com/a?", want: "https://example.com/a"},
+		{name: "query untouched by default", opts: plain, raw: "https://example.com/?b=2&utm_source=x&a=1", want: "https://example.com/?b=2&utm_source=x&a=1"},
+		{name: "query sorted", opts: CanonicalOptions{SortQuery: true}, raw: "https://example.com/?b=2&a=1&b=1", want: "https://example.com/?a=1&b=2&b=1"},
+		{name: "tracking stripped", opts: CanonicalOptions{StripTracking: true}, raw: "https://example.com/?id=7&UTM_Source=x&fbclid=y&gclid=z", want: "https://example.com/?id=7"},
+		{name: "only tracking", opts: full, raw: "https://example.com/a?utm_medium=x&utm_campaign=y", want: "https://example.com/a"},
+		{name: "escaped tracking key", opts: full, raw: "https://example.com/?utm%5Fsource=x&id=7", want: "https://example.com/?id=7"},
+		{name: "lookalike key kept", opts: full, raw: "https://example.com/?utmost=1&ref=2", want: "https://example.com/?ref=2&utmost=1"},
+		{name: "custom tracking list", opts: CanonicalOptions{StripTracking: true, TrackingParams: []string{"ref", "sess*"}}, raw: "https://example.com/?ref=a&session_id=b&utm_source=c", want: "https://example.com/?utm_source=c"},
+		{name: "fragment kept", opts: full, raw: "https://example.com/a?gclid=1#top", want: "https://example.com/a#top"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := canonicalizeURL(tt.raw)
+			if err != nil {
+				t.Fatalf("canonicalizeURL: %v", err)
+			}
+			if got := NewCanonicalizer(tt.opts).Canonicalize(u); got != tt.want {
+				t.Errorf("Canonicalize = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// Policies for links that point at this service or at another shortener
+const (
+	SelfLinkResolve = "resolve" // replace with the target of our own link
+	SelfLinkReject  = "reject"
+
+	ShortenerWarn   = "warn" // accept and show the warning page
+	ShortenerReject = "reject"
+	ShortenerExpand = "expand" // follow redirects to the final destination
+)
+
+const maxChainHops = 5
+
+// resolveDestination vets raw and unwraps links to this service or to known
+// shorteners according to the configured policies. Every hop goes through the
+// destination policy before it is looked up or requested.
+func (uc *URLShortenerUseCase) resolveDestination(ctx context.Context, raw string) (string, *url.URL, error) {
+	current := strings.TrimSpace(raw)
+
+	for hop := 0; ; hop++ {
+		if hop > maxChainHops {
+			return "", nil, fmt.Errorf("%w: more than %d redirects", ErrRedirectLoop, maxChainHops)
+		}
+
+		target, err := uc.policy.Parse(current)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// checked before Vet: our own host is usually private in development
+		domainID, self, err := uc.selfLinkDomain(ctx, target)
+		if err != nil {
+			return "", nil, err
+		}
+		if self {
+			if uc.settings.SelfLinkPolicy == SelfLinkReject {
+				return "", nil, ErrRedirectLoop
+			}
+			current, err = uc.resolveSelfLink(ctx, target, domainID)
+			if err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+
+		if err := uc.policy.Vet(ctx, target); err != nil {
+			return "", nil, err
+		}
+
+		if uc.policy.IsShortener(target) {
+			switch uc.settings.ShortenerPolicy {
+			case ShortenerReject:
+				return "", nil, ErrShortenerChain
+			case ShortenerExpand:
+				if uc.resolver == nil {
+					return "", nil, fmt.Errorf("%w: expanding is not configured", ErrShortenerChain)
+				}
+				next, err := uc.resolver.Resolve(ctx, target.String())
+				if err != nil {
+					zlog.Logger.Warn().Err(err).Str("url", target.String()).Msg("failed to expand short link")
+					return "", nil, fmt.Errorf("%w: could not be expanded", ErrShortenerChain)
+				}
+				if next == "" {
+					return "", nil, fmt.Errorf("%w: short link does not redirect anywhere", ErrShortenerChain)
+				}
+				current = next
+				continue
+			}
+		}
+
+		return current, target, nil
+	}
+}
+
+// selfLinkDomain reports whether target is served by this service, on its own
+// host or on a verified custom domain, and on which domain
+func (uc *URLShortenerUseCase) selfLinkDomain(ctx context.Context, target *url.URL) (int64, bool, error) {
+	if uc.baseURL != nil && strings.EqualFold(target.Host, uc.baseURL.Host) {
+		return domain.DefaultDomain, true, nil
+	}
+	if uc.domains == nil {
+		return 0, false, nil
+	}
+
+	d, err := uc.domains.FindByHost(ctx, domain.AllWorkspaces, domain.NormalizeHost(target.Host))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("repo error finding domain")
+		return 0, false, fmt.Errorf("database error")
+	}
+	if d == nil || !d.Verified() {
+		return 0, false, nil
+	}
+	return d.ID, true, nil
+}
+
+// resolveSelfLink maps {base}/s/{short} (or /p/{short}) on domainID to the destination of that link
+func (uc *URLShortenerUseCase) resolveSelfLink(ctx context.Context, target *url.URL, domainID int64) (string, error) {
+	path := target.Path
+	if domainID == domain.DefaultDomain {
+		path = strings.TrimPrefix(path, strings.TrimRight(uc.baseURL.Path, "/"))
+	}
+
+	var short string
+	for _, prefix := range []string{"/s/", "/p/"} {
+		if strings.HasPrefix(path, prefix) {
+			short = strings.SplitN(strings.TrimPrefix(path, prefix), "/", 2)[0]
+			break
+		}
+	}
+	short = domain.NormalizeShortCode(strings.TrimSuffix(short, "+"))
+	if short == "" {
+		return "", fmt.Errorf("%w: not a short link", ErrRedirectLoop)
+	}
+
+	code, signed, err := uc.openShort(short)
+	if err != nil {
+		return "", fmt.Errorf("%w: short link %q does not exist", ErrRedirectLoop, short)
+	}
+
+	existing, err := uc.repo.FindByShort(ctx, domain.AllWorkspaces, domainID, code)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("repo error resolving self link")
+		return "", fmt.Errorf("database error")
+	}
+	if !reachable(existing, signed) {
+		return "", fmt.Errorf("%w: short link %q does not exist", ErrRedirectLoop, short)
+	}
+	// visitors of a dead link get its fallback or an error page, not its destination
+	if existing.IsDisabled {
+		return "", fmt.Errorf("%w: short link %q is disabled", ErrRedirectLoop, short)
+	}
+	if existing.IsExpired(time.Now()) {
+		return "", fmt.Errorf("%w: short link %q has expired", ErrRedirectLoop, short)
+	}
+	return existing.Original, nil
+}
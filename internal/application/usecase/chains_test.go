@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/resolver"
+)
+
+const testShortenerHost = "short.test"
+
+// shortenerServer serves http://short.test through httptest and records the
+// paths it was asked for
+type shortenerServer struct {
+	srv  *httptest.Server
+	mu   sync.Mutex
+	hits []string
+}
+
+func newShortenerServer(t *testing.T, handler http.HandlerFunc) *shortenerServer {
+	t.Helper()
+	s := &shortenerServer{}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits = append(s.hits, r.Host+r.URL.Path)
+		s.mu.Unlock()
+		handler(w, r)
+	}))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// resolver dials the test server whatever the host, so a hop that was not
+// vetted away shows up in hits
+func (s *shortenerServer) resolver() ports.RedirectResolver {
+	addr := s.srv.Listener.Addr().String()
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+	return resolver.NewHTTPResolver(&http.Client{Transport: transport}, time.Second)
+}
+
+func (s *shortenerServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.hits...)
+}
+
+func newChainUseCase(policy PolicyOptions, settings Settings, r ports.RedirectResolver) *URLShortenerUseCase {
+	policy.ShortenerHosts = []string{testShortenerHost}
+	return NewURLShortenerUseCase(nil, nil, nil).
+		WithDestinationPolicy(NewDestinationPolicy(policy, nil, nil, nil)).
+		WithRedirectResolver(r).
+		WithSettings(settings)
+}
+
+func TestResolveDestinationExpandsShortenerChain(t *testing.T) {
+	s := newShortenerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "https://example.com/final?x=1", http.StatusFound)
+		}
+	})
+	uc := newChainUseCase(PolicyOptions{}, Settings{ShortenerPolicy: ShortenerExpand}, s.resolver())
+
+	got, target, err := uc.resolveDestination(context.Background(), "http://short.test/a")
+	if err != nil {
+		t.Fatalf("resolveDestination: %v", err)
+	}
+	if got != "https://example.com/final?x=1" || target.Host != "example.com" {
+		t.Errorf("resolveDestination = %q (host %q), want https://example.com/final?x=1", got, target.Host)
+	}
+	if hits := s.requests(); len(hits) != 2 {
+		t.Errorf("requests = %v, want one per hop", hits)
+	}
+}
+
+func TestResolveDestinationVetsEveryHop(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		policy   PolicyOptions
+	}{
+		{name: "private address", location: "http://10.0.0.1/admin"},
+		{name: "loopback", location: "http://127.0.0.1:6379/"},
+		{name: "internal name", location: "http://metadata.internal/latest"},
+		{name: "denied domain", location: "https://evil.example/x", policy: PolicyOptions{DenyDomains: []string{"evil.example"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShortenerServer(t, func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, tt.location, http.StatusFound)
+			})
+			uc := newChainUseCase(tt.policy, Settings{ShortenerPolicy: ShortenerExpand}, s.resolver())
+
+			_, _, err := uc.resolveDestination(context.Background(), "http://short.test/abc")
+			if !errors.Is(err, ErrUnsafeDestination) {
+				t.Fatalf("resolveDestination error = %v, want ErrUnsafeDestination", err)
+			}
+			if hits := s.requests(); len(hits) != 1 || hits[0] != "short.test/abc" {
+				t.Errorf("requests = %v, want only the short link", hits)
+			}
+		})
+	}
+}
+
+func TestResolveDestinationStopsAfterMaxHops(t *testing.T) {
+	s := newShortenerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		http.Redirect(w, r, "/"+strconv.Itoa(n+1), http.StatusFound)
+	})
+	uc := newChainUseCase(PolicyOptions{}, Settings{ShortenerPolicy: ShortenerExpand}, s.resolver())
+
+	_, _, err := uc.resolveDestination(context.Background(), "http://short.test/0")
+	if !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("resolveDestination error = %v, want ErrRedirectLoop", err)
+	}
+	if hits := s.requests(); len(hits) != maxChainHops+1 {
+		t.Errorf("%d requests, want %d", len(hits), maxChainHops+1)
+	}
+}
+
+func TestResolveDestinationShortenerPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		redirect bool
+		want     string
+		wantErr  error
+		wantHits int
+	}{
+		{name: "warn keeps the link", policy: ShortenerWarn, redirect: true, want: "http://short.test/abc"},
+		{name: "reject", policy: ShortenerReject, redirect: true, wantErr: ErrShortenerChain},
+		{name: "expand", policy: ShortenerExpand, redirect: true, want: "https://example.com/", wantHits: 1},
+		{name: "expand without redirect", policy: ShortenerExpand, wantErr: ErrShortenerChain, wantHits: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShortenerServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.redirect {
+					http.Redirect(w, r, "https://example.com/", http.StatusFound)
+				}
+			})
+			uc := newChainUseCase(PolicyOptions{}, Settings{ShortenerPolicy: tt.policy}, s.resolver())
+
+			got, _, err := uc.resolveDestination(context.Background(), "http://short.test/abc")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveDestination error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDestination = %q, want %q", got, tt.want)
+			}
+			if hits := s.requests(); len(hits) != tt.wantHits {
+				t.Errorf("requests = %v, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestResolveDestinationExpandWithoutResolver(t *testing.T) {
+	uc := newChainUseCase(PolicyOptions{}, Settings{ShortenerPolicy: ShortenerExpand}, nil)
+
+	if _, _, err := uc.resolveDestination(context.Background(), "http://short.test/abc"); !errors.Is(err, ErrShortenerChain) {
+		t.Fatalf("resolveDestination error = %v, want ErrShortenerChain", err)
+	}
+}
+
+// selfLinkRepo holds the links on the default domain by code
+type selfLinkRepo struct {
+	ports.URLRepository
+	links map[string]*domain.URL
+}
+
+func (r *selfLinkRepo) FindByShort(_ context.Context, _, domainID int64, short string) (*domain.URL, error) {
+	if domainID != domain.DefaultDomain {
+		return nil, nil
+	}
+	return r.links[short], nil
+}
+
+func TestResolveDestinationSelfLinks(t *testing.T) {
+	repo := &selfLinkRepo{links: map[string]*domain.URL{
+		"live":     {Short: "live", Original: "https://example.com/live"},
+		"off":      {Short: "off", Original: "https://example.com/off", IsDisabled: true},
+		"old":      {Short: "old", Original: "https://example.com/old", ExpiresAt: time.Now().Add(-time.Hour)},
+		"loop":     {Short: "loop", Original: "https://sho.example/go/s/live"},
+		"fallback": {Short: "fallback", Original: "https://example.com/x", IsDisabled: true, FallbackURL: "https://example.com/fallback"},
+	}}
+
+	tests := []struct {
+		name    string
+		policy  string
+		url     string
+		want    string
+		wantErr error
+	}{
+		{name: "resolves", url: "https://sho.example/go/s/live", want: "https://example.com/live"},
+		{name: "preview page", url: "https://sho.example/go/p/live", want: "https://example.com/live"},
+		{name: "nested", url: "https://sho.example/go/s/loop", want: "https://example.com/live"},
+		{name: "disabled", url: "https://sho.example/go/s/off", wantErr: ErrRedirectLoop},
+		{name: "disabled with fallback", url: "https://sho.example/go/s/fallback", wantErr: ErrRedirectLoop},
+		{name: "expired", url: "https://sho.example/go/s/old", wantErr: ErrRedirectLoop},
+		{name: "unknown", url: "https://sho.example/go/s/nope", wantErr: ErrRedirectLoop},
+		{name: "not a short link", url: "https://sho.example/go/links", wantErr: ErrRedirectLoop},
+		{name: "reject policy", policy: SelfLinkReject, url: "https://sho.example/go/s/live", wantErr: ErrRedirectLoop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewURLShortenerUseCase(repo, nil, nil).
+				WithSettings(Settings{BaseURL: "https://sho.example/go", SelfLinkPolicy: tt.policy})
+
+			got, _, err := uc.resolveDestination(context.Background(), tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveDestination error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDestination = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+var (
+	// blockedStems are rejected anywhere inside a code, also when spelled with
+	// lookalike digits ("5h1t"). Extended with shortener.blocked_words.
+	blockedStems = []string{
+		"bitch", "blyat", "cunt", "dildo", "fuck", "jizz", "mudak", "nigg", "penis", "pizd",
+		"porn", "pussy", "retard", "shit", "slut", "twat", "vagina", "wank", "whore",
+	}
+	// blockedWords are too short to match inside custom codes ("class", "title"),
+	// so there they only match a whole code or a part between "-" and "_".
+	// Generated codes are checked for them anywhere.
+	blockedWords = []string{
+		"anal", "anus", "arse", "ass", "boob", "cock", "crap", "cum", "damn", "dick",
+		"ebat", "fag", "hui", "huy", "kike", "nazi", "piss", "rape", "sex", "spic",
+		"suka", "tit",
+	}
+)
+
+// lookalikes map digits to the letters they imitate. '1' reads as both "i"
+// and "l", so codes are checked in both readings.
+var lookalikes = []*strings.Replacer{
+	strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g"),
+	strings.NewReplacer("0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g"),
+}
+
+// CodeFilterOptions configure CodeFilter
+type CodeFilterOptions struct {
+	Reserved []string // codes nobody may claim, matched case-insensitively
+	Blocked  []string // offensive words matched anywhere in a code, added to the built-in list
+}
+
+// CodeFilter rejects short codes that are reserved (routes, config, admin list)
+// or contain offensive words. It applies to custom and generated codes alike.
+type CodeFilter struct {
+	words ports.ReservedWordRepository
+	stems []string
+
+	mu       sync.RWMutex
+	reserved map[string]string // word -> source
+}
+
+// NewCodeFilter builds a filter, words may be nil to disable admin-managed words
+func NewCodeFilter(words ports.ReservedWordRepository, opts CodeFilterOptions) *CodeFilter {
+	f := &CodeFilter{
+		words:    words,
+		reserved: make(map[string]string),
+	}
+	f.add(domain.ReservedByConfig, opts.Reserved...)
+
+	f.stems = append(f.stems, blockedStems...)
+	for _, w := range opts.Blocked {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			f.stems = append(f.stems, w)
+		}
+	}
+	return f
+}
+
+// ReserveRoutes adds the first segments of the service's routes, e.g. "admin" for /admin/...
+func (f *CodeFilter) ReserveRoutes(words ...string) {
+	f.add(domain.ReservedByRoute, words...)
+}
+
+func (f *CodeFilter) add(source string, words ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" {
+			continue
+		}
+		// route words win, they cannot be removed
+		if _, ok := f.reserved[w]; !ok || source == domain.ReservedByRoute {
+			f.reserved[w] = source
+		}
+	}
+}
+
+// builtinSource reports where a word reserved by routes or config comes from
+func (f *CodeFilter) builtinSource(word string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	source, ok := f.reserved[word]
+	return source, ok
+}
+
+// Check returns ErrInvalidCustomShort with a reason if code may not be used.
+// generated enables the stricter blocked word matching for generated codes.
+func (f *CodeFilter) Check(ctx context.Context, code string, generated bool) error {
+	lower := strings.ToLower(domain.NormalizeShortCode(code))
+
+	if _, ok := f.builtinSource(lower); ok {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidCustomShort, code)
+	}
+
+	if f.words != nil {
+		reserved, err := f.words.Contains(ctx, lower)
+		if err != nil {
+			// the built-in checks above already ran, don't block shortening on this
+			zlog.Logger.Warn().Err(err).Msg("failed to check reserved words")
+		} else if reserved {
+			return fmt.Errorf("%w: %q is reserved", ErrInvalidCustomShort, code)
+		}
+	}
+
+	if f.isOffensive(lower, generated) {
+		return fmt.Errorf("%w: contains a blocked word", ErrInvalidCustomShort)
+	}
+	return nil
+}
+
+func (f *CodeFilter) isOffensive(lower string, generated bool) bool {
+	for _, r := range lookalikes {
+		normalized := r.Replace(lower)
+		joined := strings.NewReplacer("-", "", "_", "").Replace(normalized)
+
+		for _, w := range f.stems {
+			if strings.Contains(joined, w) {
+				return true
+			}
+		}
+
+		if generated {
+			for _, w := range blockedWords {
+				if strings.Contains(joined, w) {
+					return true
+				}
+			}
+			continue
+		}
+
+		parts := strings.FieldsFunc(normalized, func(r rune) bool { return r == '-' || r == '_' })
+		for _, part := range append(parts, joined) {
+			for _, w := range blockedWords {
+				if part == w {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// List returns route, config and admin words sorted alphabetically
+func (f *CodeFilter) List(ctx context.Context) ([]domain.ReservedWord, error) {
+	f.mu.RLock()
+	words := make([]domain.ReservedWord, 0, len(f.reserved))
+	for w, source := range f.reserved {
+		words = append(words, domain.ReservedWord{Word: w, Source: source})
+	}
+	f.mu.RUnlock()
+
+	if f.words != nil {
+		stored, err := f.words.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range stored {
+			if _, ok := f.builtinSource(w.Word); !ok {
+				words = append(words, w)
+			}
+		}
+	}
+
+	sort.Slice(words, func(i, j int) bool { return words[i].Word < words[j].Word })
+	return words, nil
+}
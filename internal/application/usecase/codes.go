@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	defaultCodeMaxAttempts = 5
+	defaultCodeGrowAfter   = 2
+)
+
+// createWithGeneratedCode saves u under a generated code. Collisions and codes
+// rejected by the code filter are retried, every CodeGrowAfter attempts the
+// generator is asked for longer codes. event is recorded with the link.
+func (uc *URLShortenerUseCase) createWithGeneratedCode(ctx context.Context, u *domain.URL, event *domain.AuditEvent) error {
+	for attempt := 0; attempt < uc.settings.CodeMaxAttempts; attempt++ {
+		short, err := uc.generateCode(ctx, attempt/uc.settings.CodeGrowAfter)
+		if err != nil {
+			zlog.Logger.Error().Err(err).Msg("failed to generate short code")
+			return fmt.Errorf("failed to generate short code")
+		}
+		if err := uc.codeFilter.Check(ctx, short, true); err != nil {
+			zlog.Logger.Debug().Err(err).Msg("generated short code rejected by filter")
+			continue
+		}
+
+		u.Short = short
+		err = uc.repo.Create(ctx, u, event)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, domain.ErrShortConflict) {
+			zlog.Logger.Error().Err(err).Msg("failed to save URL")
+			return fmt.Errorf("failed to save URL")
+		}
+		zlog.Logger.Warn().Str("short", short).Int("attempt", attempt+1).Msg("generated short code collision")
+	}
+
+	zlog.Logger.Error().Int("attempts", uc.settings.CodeMaxAttempts).Msg("no free short code found")
+	return fmt.Errorf("failed to generate a free short code")
+}
+
+func (uc *URLShortenerUseCase) generateCode(ctx context.Context, grow int) (string, error) {
+	if uc.generator == nil {
+		return GenerateShortCode()
+	}
+	return uc.generator.Generate(ctx, grow)
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"golang.org/x/net/idna"
+)
+
+const (
+	defaultMaxURLLength = 2048
+	resolveTimeout      = 2 * time.Second
+)
+
+var (
+	defaultShortenerHosts = []string{
+		"bit.ly", "bitly.com", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd",
+		"buff.ly", "rebrand.ly", "cutt.ly", "shorturl.at", "rb.gy", "tiny.cc", "clck.ru",
+	}
+	defaultWarnExtensions = []string{
+		".exe", ".msi", ".bat", ".cmd", ".com", ".scr", ".pif", ".ps1", ".vbs", ".js",
+		".jar", ".apk", ".dmg", ".pkg", ".deb", ".rpm", ".sh", ".iso",
+	}
+)
+
+// PolicyOptions configure DestinationPolicy
+type PolicyOptions struct {
+	AllowDomains      []string // if set, only these domains (and subdomains) are accepted
+	DenyDomains       []string
+	AllowPrivateHosts bool // accept loopback, private and link-local targets
+	ResolveHosts      bool // resolve host names and reject ones pointing to private addresses
+	MaxURLLength      int
+	ShortenerHosts    []string // other URL shorteners, flagged as shortener chains
+	WarnExtensions    []string // downloadable file types flagged as risky
+}
+
+// DestinationPolicy decides whether a URL may be used as a short link destination
+type DestinationPolicy struct {
+	opts       PolicyOptions
+	allow      []string
+	deny       []string
+	shorteners []string
+	extensions []string
+	blocklist  ports.Blocklist
+	warnlist   ports.Blocklist
+	resolver   ports.HostResolver
+}
+
+// NewDestinationPolicy builds a policy. blocklist hosts are rejected, warnlist hosts
+// (e.g. a feed of newly registered domains) are only flagged; both may be nil.
+func NewDestinationPolicy(opts PolicyOptions, blocklist, warnlist ports.Blocklist, resolver ports.HostResolver) *DestinationPolicy {
+	if opts.MaxURLLength <= 0 {
+		opts.MaxURLLength = defaultMaxURLLength
+	}
+	if len(opts.ShortenerHosts) == 0 {
+		opts.ShortenerHosts = defaultShortenerHosts
+	}
+	if len(opts.WarnExtensions) == 0 {
+		opts.WarnExtensions = defaultWarnExtensions
+	}
+
+	extensions := make([]string, 0, len(opts.WarnExtensions))
+	for _, ext := range opts.WarnExtensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if ext != "" {
+			extensions = append(extensions, ext)
+		}
+	}
+
+	return &DestinationPolicy{
+		opts:       opts,
+		allow:      normalizeDomains(opts.AllowDomains),
+		deny:       normalizeDomains(opts.DenyDomains),
+		shorteners: normalizeDomains(opts.ShortenerHosts),
+		extensions: extensions,
+		blocklist:  blocklist,
+		warnlist:   warnlist,
+		resolver:   resolver,
+	}
+}
+
+// IsShortener reports whether u points at a known third-party URL shortener
+func (p *DestinationPolicy) IsShortener(u *url.URL) bool {
+	return matchesDomain(u.Hostname(), p.shorteners)
+}
+
+// Assess returns a risk flag for a destination that passed Check but still deserves
+// a warning page, or an empty string if it looks fine.
+func (p *DestinationPolicy) Assess(u *url.URL) string {
+	host := u.Hostname()
+
+	if p.IsShortener(u) {
+		return domain.RiskShortenerChain
+	}
+
+	ext := strings.ToLower(path.Ext(u.Path))
+	for _, risky := range p.extensions {
+		if ext == risky {
+			return domain.RiskExecutable
+		}
+	}
+
+	if p.warnlist != nil && p.warnlist.Contains(host) {
+		return domain.RiskNewDomain
+	}
+
+	return ""
+}
+
+// Check parses and canonicalizes raw and returns the canonical URL if it is acceptable
+func (p *DestinationPolicy) Check(ctx context.Context, raw string) (*url.URL, error) {
+	u, err := p.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Vet(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Parse performs the syntactic part of Check without looking at the host
+func (p *DestinationPolicy) Parse(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > p.opts.MaxURLLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidURL, p.opts.MaxURLLength)
+	}
+	return canonicalizeURL(raw)
+}
+
+// Vet applies the host rules (private addresses, allow/deny lists, blocklist) to a parsed URL
+func (p *DestinationPolicy) Vet(ctx context.Context, u *url.URL) error {
+	return p.checkHost(ctx, u.Hostname())
+}
+
+// canonicalizeURL parses an absolute http(s) URL, lowercases scheme and host,
+// converts internationalized hosts to punycode and drops the default port.
+// URLs with credentials are refused.
+func canonicalizeURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, ErrURLRequired
+	}
+	if strings.ContainsAny(raw, "\\\x00\t\r\n ") {
+		return nil, fmt.Errorf("%w: contains whitespace or backslashes", ErrInvalidURL)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: only http and https are allowed", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials in URL are not allowed", ErrUnsafeDestination)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return nil, fmt.Errorf("%w: host is required", ErrInvalidURL)
+	}
+	host, err = asciiHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid host name: %v", ErrInvalidURL, err)
+	}
+
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	u.Host = host
+	if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	}
+	if port != "" {
+		u.Host += ":" + port
+	}
+
+	return u, nil
+}
+
+// asciiHost converts an internationalized host name to punycode. ASCII hosts are
+// returned as is so names the IDNA rules reject (e.g. with underscores) keep working.
+func asciiHost(host string) (string, error) {
+	for i := 0; i < len(host); i++ {
+		if host[i] >= utf8.RuneSelf {
+			return idna.Lookup.ToASCII(host)
+		}
+	}
+	return host, nil
+}
+
+func (p *DestinationPolicy) checkHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !p.opts.AllowPrivateHosts && domain.IsPrivateAddr(addr) {
+			return fmt.Errorf("%w: private or loopback address", ErrUnsafeDestination)
+		}
+	} else {
+		if looksLikeNumericHost(host) {
+			return fmt.Errorf("%w: obfuscated IP address", ErrUnsafeDestination)
+		}
+		if !p.opts.AllowPrivateHosts && isInternalName(host) {
+			return fmt.Errorf("%w: internal host name", ErrUnsafeDestination)
+		}
+	}
+
+	if len(p.allow) > 0 && !matchesDomain(host, p.allow) {
+		return fmt.Errorf("%w: domain is not in the allow list", ErrUnsafeDestination)
+	}
+	if matchesDomain(host, p.deny) {
+		return fmt.Errorf("%w: domain is denied", ErrUnsafeDestination)
+	}
+	if p.blocklist != nil && p.blocklist.Contains(host) {
+		return fmt.Errorf("%w: domain is on the blocklist", ErrUnsafeDestination)
+	}
+
+	if p.opts.ResolveHosts && !p.opts.AllowPrivateHosts && p.resolver != nil {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return p.checkResolved(ctx, host)
+		}
+	}
+
+	return nil
+}
+
+func (p *DestinationPolicy) checkResolved(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	addrs, err := p.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		// unresolvable hosts are not dangerous by themselves, the link may start working later
+		zlog.Logger.Debug().Err(err).Str("host", host).Msg("failed to resolve destination host")
+		return nil
+	}
+
+	for _, a := range addrs {
+		addr, ok := netip.AddrFromSlice(a.IP)
+		if ok && domain.IsPrivateAddr(addr) {
+			return fmt.Errorf("%w: host resolves to a private address", ErrUnsafeDestination)
+		}
+	}
+	return nil
+}
+
+func isInternalName(host string) bool {
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeNumericHost catches decimal, octal and hex IPv4 spellings such as
+// 2130706433 or 0x7f.1 that browsers still resolve to an address.
+func looksLikeNumericHost(host string) bool {
+	labels := strings.Split(host, ".")
+	last := labels[len(labels)-1]
+	if last == "" {
+		return false
+	}
+	if strings.HasPrefix(last, "0x") {
+		return true
+	}
+	for _, r := range last {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeDomains(domains []string) []string {
+	out := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.Trim(strings.ToLower(strings.TrimSpace(d)), ".")
+		if ascii, err := asciiHost(d); err == nil {
+			d = ascii
+		}
+		if d != "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// matchesDomain reports whether host equals one of domains or is a subdomain of it
+func matchesDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const domainTokenBytes = 24
+
+// DomainService manages the custom domains of workspaces and proves they
+// point at the service before links may use them
+type DomainService struct {
+	domains     ports.DomainRepository
+	verifier    ports.DomainVerifier
+	serviceHost string
+}
+
+// NewDomainService keeps serviceHost, the service's own host, from being
+// registered as a custom domain
+func NewDomainService(domains ports.DomainRepository, verifier ports.DomainVerifier, serviceHost string) *DomainService {
+	return &DomainService{domains: domains, verifier: verifier, serviceHost: domain.NormalizeHost(serviceHost)}
+}
+
+// Add registers host for the actor's workspace, unverified. Owners and editors may.
+func (s *DomainService) Add(ctx context.Context, actor domain.Actor, host string) (*domain.CustomDomain, error) {
+	if err := requireWriter(actor); err != nil {
+		return nil, err
+	}
+	if actor.WorkspaceID == 0 {
+		return nil, fmt.Errorf("%w: pick the workspace with the X-Workspace-ID header", ErrInvalidDomain)
+	}
+	host = domain.NormalizeHost(host)
+	if err := domain.ValidateHost(host); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+	}
+	if host == s.serviceHost {
+		return nil, fmt.Errorf("%w: %s is the service's own host", ErrInvalidDomain, host)
+	}
+	// unverified claims of other workspaces do not block the host
+	existing, err := s.domains.FindByHost(ctx, domain.AllWorkspaces, host)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("repo error finding domain")
+		return nil, fmt.Errorf("failed to add domain")
+	}
+	if existing != nil && existing.Verified() && existing.WorkspaceID != actor.WorkspaceID {
+		return nil, ErrDomainTaken
+	}
+
+	token, err := newDomainToken()
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to generate domain token")
+		return nil, fmt.Errorf("failed to add domain")
+	}
+
+	d := &domain.CustomDomain{
+		WorkspaceID:       actor.WorkspaceID,
+		Host:              host,
+		VerificationToken: token,
+		CreatedAt:         time.Now(),
+	}
+	if err := s.domains.Create(ctx, d); err != nil {
+		if errors.Is(err, domain.ErrDomainTaken) {
+			return nil, ErrDomainTaken
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to add domain")
+		return nil, fmt.Errorf("failed to add domain")
+	}
+	return d, nil
+}
+
+// List returns the domains of the actor's workspace to any of its members
+func (s *DomainService) List(ctx context.Context, actor domain.Actor) ([]*domain.CustomDomain, error) {
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := s.domains.List(ctx, scope)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list domains")
+		return nil, fmt.Errorf("failed to list domains")
+	}
+	return domains, nil
+}
+
+// Verify fetches the token the domain serves at domain.DomainVerificationPath
+// and marks the domain verified if it matches. The domain's own web server has
+// to serve it, afterwards the domain can point at the service.
+func (s *DomainService) Verify(ctx context.Context, actor domain.Actor, id int64) (*domain.CustomDomain, error) {
+	if err := requireWriter(actor); err != nil {
+		return nil, err
+	}
+	d, err := s.find(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Verified() {
+		return d, nil
+	}
+
+	token, err := s.verifier.FetchToken(ctx, d.Host)
+	if err != nil {
+		zlog.Logger.Info().Err(err).Str("host", d.Host).Msg("domain verification failed")
+		return nil, fmt.Errorf("%w: %s%s could not be fetched: %v", ErrDomainNotVerified, d.Host, domain.DomainVerificationPath, err)
+	}
+	if token != d.VerificationToken {
+		return nil, fmt.Errorf("%w: %s%s does not serve the verification token", ErrDomainNotVerified, d.Host, domain.DomainVerificationPath)
+	}
+
+	d.VerifiedAt = time.Now()
+	if err := s.domains.MarkVerified(ctx, d.ID, d.VerifiedAt); err != nil {
+		if errors.Is(err, domain.ErrDomainTaken) {
+			return nil, fmt.Errorf("%w: another workspace verified %s", ErrDomainTaken, d.Host)
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to mark domain verified")
+		return nil, fmt.Errorf("failed to verify domain")
+	}
+	return d, nil
+}
+
+// Delete removes a domain that has no links left, for the same callers as Add
+func (s *DomainService) Delete(ctx context.Context, actor domain.Actor, id int64) error {
+	if err := requireWriter(actor); err != nil {
+		return err
+	}
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.domains.Delete(ctx, scope, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrDomainInUse) {
+			return fmt.Errorf("%w: delete or move its links first", ErrDomainInUse)
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to delete domain")
+		return fmt.Errorf("failed to delete domain")
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *DomainService) find(ctx context.Context, actor domain.Actor, id int64) (*domain.CustomDomain, error) {
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.domains.FindByID(ctx, scope, id)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("repo error finding domain")
+		return nil, fmt.Errorf("database error")
+	}
+	if d == nil {
+		return nil, ErrNotFound
+	}
+	return d, nil
+}
+
+func newDomainToken() (string, error) {
+	b := make([]byte, domainTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// visitorDomain maps the host a visitor came in on to the domain whose codes
+// it serves. The service's own host, unknown hosts and unverified domains
+// serve the default domain.
+func (uc *URLShortenerUseCase) visitorDomain(ctx context.Context, host string) (int64, error) {
+	host = domain.NormalizeHost(host)
+	if uc.domains == nil || host == "" || (uc.baseURL != nil && host == domain.NormalizeHost(uc.baseURL.Host)) {
+		return domain.DefaultDomain, nil
+	}
+
+	d, err := uc.domains.FindByHost(ctx, domain.AllWorkspaces, host)
+	if err != nil {
+		return 0, err
+	}
+	if d == nil || !d.Verified() {
+		return domain.DefaultDomain, nil
+	}
+	return d.ID, nil
+}
+
+// linkDomainID maps the host of a custom domain within scope to its id, an
+// empty host is the default domain. Services outside the use case name links
+// by domain with it.
+func linkDomainID(ctx context.Context, domains ports.DomainRepository, scope int64, host string) (int64, error) {
+	if host == "" {
+		return domain.DefaultDomain, nil
+	}
+	if domains == nil {
+		return 0, fmt.Errorf("%w: unknown domain %s", ErrNotFound, host)
+	}
+
+	d, err := domains.FindByHost(ctx, scope, domain.NormalizeHost(host))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("repo error finding domain")
+		return 0, fmt.Errorf("database error")
+	}
+	if d == nil {
+		return 0, fmt.Errorf("%w: unknown domain %s", ErrNotFound, host)
+	}
+	return d.ID, nil
+}
+
+// managedDomain looks up the domain a manager names by host within scope,
+// an empty host is the default domain and gives nil
+func (uc *URLShortenerUseCase) managedDomain(ctx context.Context, scope int64, host string) (*domain.CustomDomain, error) {
+	if host == "" {
+		return nil, nil
+	}
+	if uc.domains == nil {
+		return nil, fmt.Errorf("%w: unknown domain %s", ErrNotFound, host)
+	}
+
+	d, err := uc.domains.FindByHost(ctx, scope, domain.NormalizeHost(host))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("repo error finding domain")
+		return nil, fmt.Errorf("database error")
+	}
+	if d == nil {
+		return nil, fmt.Errorf("%w: unknown domain %s", ErrNotFound, host)
+	}
+	return d, nil
+}
+
+// findManaged looks up a link a manager names by code and domain host within
+// scope, it fails with ErrNotFound if there is none
+func (uc *URLShortenerUseCase) findManaged(ctx context.Context, scope int64, host, short string) (*domain.URL, error) {
+	d, err := uc.managedDomain(ctx, scope, host)
+	if err != nil {
+		return nil, err
+	}
+	domainID := domain.DefaultDomain
+	if d != nil {
+		domainID = d.ID
+	}
+
+	u, err := uc.repo.FindByShort(ctx, scope, domainID, short)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("short", short).Msg("repo error finding URL")
+		return nil, fmt.Errorf("database error")
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+	return u, nil
+}
+
+// domainHost is the host of d, empty for the default domain
+func domainHost(d *domain.CustomDomain) string {
+	if d == nil {
+		return ""
+	}
+	return d.Host
+}
+
+// servedHost is the host the links of a custom domain are served on: host
+// itself, or for the default domain (empty host) the host of the base URL,
+// which stays empty when no base URL is set
+func (uc *URLShortenerUseCase) servedHost(host string) string {
+	if host == "" && uc.baseURL != nil {
+		return uc.baseURL.Host
+	}
+	return host
+}
+
+// linkHost looks up the host u is served on, see servedHost
+func (uc *URLShortenerUseCase) linkHost(ctx context.Context, u *domain.URL) string {
+	if u.DomainID == domain.DefaultDomain || uc.domains == nil {
+		return uc.servedHost("")
+	}
+	d, err := uc.domains.FindByID(ctx, domain.AllWorkspaces, u.DomainID)
+	if err != nil || d == nil {
+		zlog.Logger.Warn().Err(err).Int64("domain_id", u.DomainID).Str("short", u.Short).Msg("failed to find link domain")
+		return ""
+	}
+	return d.Host
+}
+
+// domainHosts maps the ids of the custom domains in scope to their hosts
+func (uc *URLShortenerUseCase) domainHosts(ctx context.Context, scope int64) (map[int64]string, error) {
+	hosts := make(map[int64]string)
+	if uc.domains == nil {
+		return hosts, nil
+	}
+
+	domains, err := uc.domains.List(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		hosts[d.ID] = d.Host
+	}
+	return hosts, nil
+}
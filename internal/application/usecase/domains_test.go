@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/domaincheck"
+)
+
+// domainRepo finds claims the way the postgres repository does: a verified
+// claim of a host wins over unverified ones
+type domainRepo struct {
+	ports.DomainRepository
+	claims []*domain.CustomDomain
+	taken  bool // MarkVerified finds the host verified by another workspace
+}
+
+func (r *domainRepo) FindByID(_ context.Context, workspaceID, id int64) (*domain.CustomDomain, error) {
+	for _, d := range r.claims {
+		if d.ID == id && (workspaceID == domain.AllWorkspaces || d.WorkspaceID == workspaceID) {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *domainRepo) FindByHost(_ context.Context, workspaceID int64, host string) (*domain.CustomDomain, error) {
+	var found *domain.CustomDomain
+	for _, d := range r.claims {
+		if d.Host != host || (workspaceID != domain.AllWorkspaces && d.WorkspaceID != workspaceID) {
+			continue
+		}
+		if found == nil || (d.Verified() && !found.Verified()) {
+			found = d
+		}
+	}
+	return found, nil
+}
+
+func (r *domainRepo) MarkVerified(_ context.Context, id int64, at time.Time) error {
+	if r.taken {
+		return domain.ErrDomainTaken
+	}
+	return nil
+}
+
+func TestVisitorDomain(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	repo := &domainRepo{claims: []*domain.CustomDomain{
+		{ID: 1, WorkspaceID: 3, Host: "go.shop.example", VerifiedAt: verifiedAt},
+		{ID: 2, WorkspaceID: 4, Host: "pending.example"},
+		{ID: 3, WorkspaceID: 5, Host: "go.shop.example"}, // a squatter's unverified claim
+		{ID: 4, WorkspaceID: 5, Host: "links.example"},
+		{ID: 5, WorkspaceID: 6, Host: "links.example", VerifiedAt: verifiedAt},
+	}}
+
+	tests := []struct {
+		name string
+		host string
+		want int64
+	}{
+		{name: "service host", host: "sho.example", want: domain.DefaultDomain},
+		{name: "service host with case and port", host: "SHO.example:443", want: domain.DefaultDomain},
+		{name: "no host", host: "", want: domain.DefaultDomain},
+		{name: "unknown host", host: "other.example", want: domain.DefaultDomain},
+		{name: "unverified domain", host: "pending.example", want: domain.DefaultDomain},
+		{name: "verified domain", host: "go.shop.example", want: 1},
+		{name: "verified domain with port", host: "Go.Shop.Example:443", want: 1},
+		{name: "verified claim wins", host: "links.example", want: 5},
+	}
+	uc := NewURLShortenerUseCase(nil, nil, nil).
+		WithDomains(repo).
+		WithSettings(Settings{BaseURL: "https://sho.example"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uc.visitorDomain(context.Background(), tt.host)
+			if err != nil {
+				t.Fatalf("visitorDomain: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("visitorDomain(%q) = %d, want %d", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisitorDomainWithoutDomains(t *testing.T) {
+	uc := NewURLShortenerUseCase(nil, nil, nil)
+
+	if got, err := uc.visitorDomain(context.Background(), "go.shop.example"); err != nil || got != domain.DefaultDomain {
+		t.Errorf("visitorDomain = %d, %v, want the default domain", got, err)
+	}
+}
+
+func TestVerifyChecksWellKnownToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		serve   string
+		status  int
+		taken   bool
+		wantErr error
+	}{
+		{name: "token served", serve: "tok-1", status: http.StatusOK},
+		{name: "other token", serve: "tok-2", status: http.StatusOK, wantErr: ErrDomainNotVerified},
+		{name: "not served", status: http.StatusNotFound, wantErr: ErrDomainNotVerified},
+		{name: "verified elsewhere", serve: "tok-1", status: http.StatusOK, taken: true, wantErr: ErrDomainTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Host != "go.shop.example" || r.URL.Path != domain.DomainVerificationPath {
+					t.Errorf("fetched %s%s", r.Host, r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.serve))
+			}))
+			defer srv.Close()
+
+			repo := &domainRepo{taken: tt.taken, claims: []*domain.CustomDomain{
+				{ID: 1, WorkspaceID: 3, Host: "go.shop.example", VerificationToken: "tok-1"},
+			}}
+			verifier := domaincheck.NewHTTPVerifier(domaincheck.HTTPVerifierOptions{Scheme: "http", Address: srv.Listener.Addr().String()})
+			s := NewDomainService(repo, verifier, "sho.example")
+
+			actor := domain.Actor{UserID: 1, WorkspaceID: 3, Role: domain.RoleOwner}
+			d, err := s.Verify(context.Background(), actor, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !d.Verified() {
+				t.Error("Verify succeeded but the domain is not verified")
+			}
+		})
+	}
+}
+
+func TestVerifyKeepsOtherWorkspacesOut(t *testing.T) {
+	repo := &domainRepo{claims: []*domain.CustomDomain{
+		{ID: 1, WorkspaceID: 3, Host: "go.shop.example", VerificationToken: "tok-1"},
+	}}
+	s := NewDomainService(repo, nil, "sho.example")
+
+	actor := domain.Actor{UserID: 2, WorkspaceID: 4, Role: domain.RoleOwner}
+	if _, err := s.Verify(context.Background(), actor, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Verify error = %v, want ErrNotFound", err)
+	}
+}
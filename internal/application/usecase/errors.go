@@ -8,5 +8,35 @@ var (
 	ErrURLRequired            = errors.New("URL is required")
 	ErrShortCodeRequired      = errors.New("short code is required")
 	ErrNotFound               = errors.New("not found")
+	ErrExpired                = errors.New("link has expired")
+	ErrDisabled               = errors.New("link is disabled")
 	ErrInvalidQuery           = errors.New("invalid query parameters")
+	ErrInvalidVariants        = errors.New("invalid variants")
+	ErrInvalidPathSuffix      = errors.New("invalid path suffix")
+	ErrInvalidUTM             = errors.New("invalid UTM parameters")
+	ErrInvalidRedirectCode    = errors.New("redirect code must be one of 301, 302, 307, 308")
+	ErrInvalidURL             = errors.New("invalid URL")
+	ErrUnsafeDestination      = errors.New("destination is not allowed")
+	ErrInvalidRiskFlag        = errors.New("invalid risk flag")
+	ErrNoWarningShown         = errors.New("warning page was not shown or has expired")
+	ErrRedirectLoop           = errors.New("destination points back at this service")
+	ErrShortenerChain         = errors.New("destination is another URL shortener")
+	ErrInvalidReservedWord    = errors.New("invalid reserved word")
+	ErrReservedWordExists     = errors.New("word is already reserved")
+	ErrReservedWordBuiltin    = errors.New("word cannot be removed")
+	ErrUnauthorized           = errors.New("authentication required")
+	ErrForbidden              = errors.New("access denied")
+	ErrInvalidAPIKey          = errors.New("invalid API key request")
+	ErrInvalidAccount         = errors.New("invalid account")
+	ErrInvalidCredentials     = errors.New("invalid email or password")
+	ErrEmailTaken             = errors.New("email is already registered")
+	ErrInvalidWorkspace       = errors.New("invalid workspace request")
+	ErrLastOwner              = errors.New("workspace must keep at least one owner")
+	ErrInvalidLinkUpdate      = errors.New("invalid link update")
+	ErrInvalidWebhook         = errors.New("invalid webhook")
+	ErrSigningDisabled        = errors.New("signed links are not enabled")
+	ErrInvalidDomain          = errors.New("invalid domain")
+	ErrDomainTaken            = errors.New("domain is already registered")
+	ErrDomainInUse            = errors.New("domain still has links")
+	ErrDomainNotVerified      = errors.New("domain is not verified")
 )
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+const (
+	defaultEventLease     = time.Minute
+	defaultEventRetention = 7 * 24 * time.Hour
+)
+
+// EventRelayOptions tune the relay, zero values fall back to defaults
+type EventRelayOptions struct {
+	Lease     time.Duration // a claimed batch is published again after this if its relay died
+	Retention time.Duration // how long published events are kept
+}
+
+// EventRelay publishes the domain events written to the outbox to the event
+// sink. Delivery is at least once: a batch that failed, or whose relay died
+// before marking it, is published again.
+type EventRelay struct {
+	outbox ports.OutboxRepository
+	sink   ports.EventSink
+	opts   EventRelayOptions
+}
+
+func NewEventRelay(outbox ports.OutboxRepository, sink ports.EventSink, opts EventRelayOptions) *EventRelay {
+	if opts.Lease <= 0 {
+		opts.Lease = defaultEventLease
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultEventRetention
+	}
+	return &EventRelay{outbox: outbox, sink: sink, opts: opts}
+}
+
+// RelayBatch publishes up to limit pending events and returns how many it published
+func (r *EventRelay) RelayBatch(ctx context.Context, limit int) (int, error) {
+	events, err := r.outbox.ClaimPending(ctx, limit, r.opts.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := r.sink.Publish(ctx, events); err != nil {
+		return 0, fmt.Errorf("publish events: %w", err)
+	}
+
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	if err := r.outbox.MarkPublished(ctx, ids); err != nil {
+		return 0, fmt.Errorf("mark events published: %w", err)
+	}
+	return len(events), nil
+}
+
+// Prune deletes events published longer ago than the retention and returns how many
+func (r *EventRelay) Prune(ctx context.Context) (int64, error) {
+	return r.outbox.DeletePublished(ctx, time.Now().Add(-r.opts.Retention))
+}
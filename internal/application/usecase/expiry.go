@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// AnnounceExpired handles up to limit links that expired since the last call:
+// the repository writes their LinkExpired events and webhooks get link.expired.
+// It returns how many links it handled.
+func (uc *URLShortenerUseCase) AnnounceExpired(ctx context.Context, limit int) (int, error) {
+	links, err := uc.repo.ClaimExpired(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range links {
+		enqueueWebhooks(ctx, uc.webhooks, domain.EventLinkExpired, u, uc.linkHost(ctx, u), nil)
+	}
+	return len(links), nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	defaultUnhealthyLimit = 100
+	maxUnhealthyLimit     = 1000
+)
+
+// LinksDueForHealthCheck returns active links not checked since checkedBefore,
+// never-checked links first and one link per host before the next. Meant for the health checker running as domain.SystemActor.
+func (uc *URLShortenerUseCase) LinksDueForHealthCheck(ctx context.Context, actor domain.Actor, checkedBefore time.Time, limit int) ([]*domain.URL, error) {
+	if err := requireAdmin(actor); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListForHealthCheck(ctx, domain.AllWorkspaces, checkedBefore, limit)
+}
+
+// CheckLinkHealth probes the link's destination and stores the outcome.
+// Any status below 400 counts as healthy.
+func (uc *URLShortenerUseCase) CheckLinkHealth(ctx context.Context, actor domain.Actor, u *domain.URL) error {
+	if err := requireAccess(actor, u); err != nil {
+		return err
+	}
+	if uc.prober == nil {
+		return fmt.Errorf("health prober is not configured")
+	}
+
+	status, latency, err := uc.prober.Probe(ctx, u.Original)
+	if err != nil {
+		zlog.Logger.Debug().Err(err).Str("short", u.Short).Msg("destination probe failed")
+		status = 0
+	}
+	healthy := err == nil && status < http.StatusBadRequest
+
+	if err := uc.repo.UpdateHealth(ctx, u.ID, status, latency.Milliseconds(), healthy, time.Now()); err != nil {
+		return fmt.Errorf("save health of %s: %w", u.Short, err)
+	}
+	return nil
+}
+
+// GetUnhealthyLinks lists the failing links of the actor's workspace
+func (uc *URLShortenerUseCase) GetUnhealthyLinks(ctx context.Context, query dto.UnhealthyLinksQuery) (dto.UnhealthyLinksResult, error) {
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return dto.UnhealthyLinksResult{}, err
+	}
+	if query.MinFailures <= 0 {
+		query.MinFailures = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultUnhealthyLimit
+	}
+	if query.Limit > maxUnhealthyLimit {
+		query.Limit = maxUnhealthyLimit
+	}
+
+	urls, err := uc.repo.ListUnhealthy(ctx, scope, query.MinFailures, query.Limit)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list unhealthy links")
+		return dto.UnhealthyLinksResult{}, fmt.Errorf("failed to get unhealthy links")
+	}
+
+	links := make([]dto.UnhealthyLink, 0, len(urls))
+	for _, u := range urls {
+		links = append(links, dto.UnhealthyLink{
+			Short:       u.Short,
+			Original:    u.Original,
+			FallbackURL: u.FallbackURL,
+			Status:      u.Health.Status,
+			LatencyMs:   u.Health.LatencyMs,
+			Failures:    u.Health.Failures,
+			CheckedAt:   u.Health.CheckedAt,
+			BrokenSince: u.Health.BrokenSince,
+		})
+	}
+
+	return dto.UnhealthyLinksResult{Links: links}, nil
+}
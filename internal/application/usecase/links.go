@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	defaultListLinksLimit = 50
+	maxListLinksLimit     = 500
+)
+
+// ListLinks returns the links of the caller's workspace, newest first
+func (uc *URLShortenerUseCase) ListLinks(ctx context.Context, query dto.ListLinksQuery) (dto.ListLinksResult, error) {
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return dto.ListLinksResult{}, err
+	}
+	var ownerID int64
+	if query.Mine {
+		if query.Actor.UserID == 0 {
+			return dto.ListLinksResult{}, denied(query.Actor)
+		}
+		ownerID = query.Actor.UserID
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultListLinksLimit
+	}
+	if query.Limit > maxListLinksLimit {
+		query.Limit = maxListLinksLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	urls, err := uc.repo.ListLinks(ctx, scope, ownerID, query.Limit, query.Offset)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Int64("workspace_id", scope).Msg("failed to list links")
+		return dto.ListLinksResult{}, fmt.Errorf("failed to list links")
+	}
+	hosts, err := uc.domainHosts(ctx, scope)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Int64("workspace_id", scope).Msg("failed to list domains")
+		return dto.ListLinksResult{}, fmt.Errorf("failed to list links")
+	}
+
+	links := make([]dto.LinkSummary, len(urls))
+	for i, u := range urls {
+		links[i] = dto.LinkSummary{
+			Short:     u.Short,
+			Original:  u.Original,
+			CreatedAt: u.CreatedAt,
+			ExpiresAt: u.ExpiresAt,
+			Visits:    u.Visits,
+			OwnerID:   u.OwnerID,
+			Disabled:  u.IsDisabled,
+			Signed:    u.Signed,
+			Domain:    hosts[u.DomainID],
+		}
+	}
+	return dto.ListLinksResult{Links: links}, nil
+}
+
+// UpdateLink changes the destination, expiry, fallback or redirect code of a
+// link, or disables and re-enables it. Owners and editors of the link's
+// workspace and admins may.
+func (uc *URLShortenerUseCase) UpdateLink(ctx context.Context, cmd dto.UpdateLinkCommand) (*domain.URL, error) {
+	u, err := uc.findForWrite(ctx, cmd.Actor, cmd.Domain, cmd.Short)
+	if err != nil {
+		return nil, err
+	}
+	editing := cmd.URL != nil || cmd.Expires != nil || cmd.FallbackURL != nil || cmd.RedirectCode != nil
+	if !editing && cmd.Disabled == nil {
+		return nil, fmt.Errorf("%w: nothing to change", ErrInvalidLinkUpdate)
+	}
+
+	before := *u
+	if cmd.URL != nil {
+		original, target, err := uc.resolveDestination(ctx, *cmd.URL)
+		if err != nil {
+			return nil, err
+		}
+		u.Original = original
+		u.Canonical = uc.canonicalizer.Canonicalize(target)
+		u.OriginalHash = destinationHash(u.Canonical)
+		if flag := uc.policy.Assess(target); flag != "" {
+			u.RiskFlag = flag
+		}
+	}
+	if cmd.Expires != nil {
+		if *cmd.Expires <= 0 {
+			return nil, fmt.Errorf("%w: expires must be a unix timestamp", ErrInvalidLinkUpdate)
+		}
+		u.ExpiresAt = time.Unix(*cmd.Expires, 0)
+	}
+	if cmd.FallbackURL != nil {
+		u.FallbackURL = ""
+		if *cmd.FallbackURL != "" {
+			target, err := uc.policy.Check(ctx, *cmd.FallbackURL)
+			if err != nil {
+				return nil, fmt.Errorf("fallback: %w", err)
+			}
+			u.FallbackURL = target.String()
+		}
+	}
+	if cmd.RedirectCode != nil {
+		if *cmd.RedirectCode != 0 && !domain.IsValidRedirectCode(*cmd.RedirectCode) {
+			return nil, ErrInvalidRedirectCode
+		}
+		u.RedirectCode = *cmd.RedirectCode
+	}
+	if cmd.Disabled != nil {
+		u.IsDisabled = *cmd.Disabled
+	}
+
+	action := domain.AuditLinkUpdated
+	if !editing {
+		action = domain.AuditLinkEnabled
+		if u.IsDisabled {
+			action = domain.AuditLinkDisabled
+		}
+	}
+
+	found, err := uc.repo.Update(ctx, u, newAuditEvent(action, cmd.Actor, cmd.Request, &before, u))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("short", u.Short).Msg("failed to update link")
+		return nil, fmt.Errorf("failed to update link")
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+
+	uc.forgetLink(ctx, u)
+	if action == domain.AuditLinkDisabled {
+		enqueueWebhooks(ctx, uc.webhooks, domain.EventLinkDisabled, u, uc.linkHost(ctx, u), nil)
+	}
+	return u, nil
+}
+
+// DeleteLink removes a link with its clicks, for the same callers as UpdateLink.
+// Its audit events are kept.
+func (uc *URLShortenerUseCase) DeleteLink(ctx context.Context, cmd dto.DeleteLinkCommand) error {
+	u, err := uc.findForWrite(ctx, cmd.Actor, cmd.Domain, cmd.Short)
+	if err != nil {
+		return err
+	}
+
+	found, err := uc.repo.Delete(ctx, u.ID, newAuditEvent(domain.AuditLinkDeleted, cmd.Actor, cmd.Request, u, nil))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("short", u.Short).Msg("failed to delete link")
+		return fmt.Errorf("failed to delete link")
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	uc.forgetLink(ctx, u)
+	return nil
+}
+
+// findForWrite looks up a link the actor wants to change
+func (uc *URLShortenerUseCase) findForWrite(ctx context.Context, actor domain.Actor, host, short string) (*domain.URL, error) {
+	short = domain.NormalizeShortCode(short)
+	if short == "" {
+		return nil, ErrShortCodeRequired
+	}
+	if err := requireWriter(actor); err != nil {
+		return nil, err
+	}
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.findManaged(ctx, scope, host, short)
+}
+
+// forgetLink drops the cached destination of a changed or deleted link
+func (uc *URLShortenerUseCase) forgetLink(ctx context.Context, u *domain.URL) {
+	if err := uc.cache.Del(ctx, cacheKey(u)); err != nil {
+		zlog.Logger.Warn().Err(err).Str("short", u.Short).Msg("failed to evict cached URL")
+	}
+}
+
+// cacheKey is the code for links of the default domain, codes on custom
+// domains are prefixed with the domain id
+func cacheKey(u *domain.URL) string {
+	if u.DomainID == domain.DefaultDomain {
+		return u.Short
+	}
+	return strconv.FormatInt(u.DomainID, 10) + "/" + u.Short
+}
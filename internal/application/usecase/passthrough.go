@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// applyPassthrough forwards the visitor's path suffix and query string to dest.
+//
+// Conflict rules:
+//   - the path suffix is appended to the destination path segment by segment,
+//     every segment is re-escaped and "." / ".." segments are rejected;
+//   - query parameters already present in the destination always win,
+//     incoming parameters with other keys are appended after them;
+//   - the destination fragment is kept as is.
+func applyPassthrough(dest string, u *domain.URL, cmd dto.RedirectCommand) (string, error) {
+	forwardPath := u.PathPassthrough && strings.Trim(cmd.PathSuffix, "/") != ""
+	forwardQuery := u.ForwardQuery && cmd.RawQuery != ""
+	if !forwardPath && !forwardQuery {
+		return dest, nil
+	}
+
+	target, err := url.Parse(dest)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination: %w", err)
+	}
+
+	if forwardPath {
+		if err := appendPath(target, cmd.PathSuffix); err != nil {
+			return "", err
+		}
+	}
+
+	if forwardQuery {
+		mergeQuery(target, cmd.RawQuery)
+	}
+
+	return target.String(), nil
+}
+
+func appendPath(target *url.URL, suffix string) error {
+	var escaped []string
+	for _, seg := range strings.Split(strings.Trim(suffix, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if seg == "." || seg == ".." {
+			return fmt.Errorf("%w: relative segments are not allowed", ErrInvalidPathSuffix)
+		}
+		escaped = append(escaped, url.PathEscape(seg))
+	}
+
+	rawPath := strings.TrimRight(target.EscapedPath(), "/") + "/" + strings.Join(escaped, "/")
+	if strings.HasSuffix(suffix, "/") {
+		rawPath += "/"
+	}
+
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPathSuffix, err)
+	}
+	target.Path = path
+	target.RawPath = rawPath
+	return nil
+}
+
+func mergeQuery(target *url.URL, rawQuery string) {
+	// ParseQuery keeps every well-formed pair even if some are malformed
+	incoming, _ := url.ParseQuery(rawQuery)
+	own := target.Query()
+
+	extra := url.Values{}
+	for key, values := range incoming {
+		if _, exists := own[key]; exists {
+			continue
+		}
+		extra[key] = values
+	}
+	appendQuery(target, extra)
+}
+
+// appendQuery adds values after the existing query without reordering it
+func appendQuery(target *url.URL, values url.Values) {
+	if len(values) == 0 {
+		return
+	}
+	if target.RawQuery == "" {
+		target.RawQuery = values.Encode()
+		return
+	}
+	target.RawQuery = strings.TrimRight(target.RawQuery, "&") + "&" + values.Encode()
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+func TestApplyPassthrough(t *testing.T) {
+	both := &domain.URL{PathPassthrough: true, ForwardQuery: true}
+
+	tests := []struct {
+		name    string
+		dest    string
+		u       *domain.URL
+		suffix  string
+		query   string
+		want    string
+		wantErr error
+	}{
+		{name: "off", dest: "https://example.com/docs", u: &domain.URL{}, suffix: "a/b", query: "x=1", want: "https://example.com/docs"},
+		{name: "path appended", dest: "https://example.com/docs", u: both, suffix: "guide/intro", want: "https://example.com/docs/guide/intro"},
+		{name: "destination trailing slash", dest: "https://example.com/docs/", u: both, suffix: "guide", want: "https://example.com/docs/guide"},
+		{name: "suffix trailing slash kept", dest: "https://example.com/docs", u: both, suffix: "guide/", want: "https://example.com/docs/guide/"},
+		{name: "empty segments dropped", dest: "https://example.com", u: both, suffix: "/a//b", want: "https://example.com/a/b"},
+		{name: "segments escaped", dest: "https://example.com/docs", u: both, suffix: "a b/c?d", want: "https://example.com/docs/a%20b/c%3Fd"},
+		{name: "dot segment", dest: "https://example.com/docs", u: both, suffix: "a/../admin", wantErr: ErrInvalidPathSuffix},
+		{name: "query added", dest: "https://example.com/p", u: both, query: "ref=mail", want: "https://example.com/p?ref=mail"},
+		{name: "destination keys win", dest: "https://example.com/p?utm_source=site&id=1", u: both, query: "utm_source=spoof&ref=mail", want: "https://example.com/p?utm_source=site&id=1&ref=mail"},
+		{name: "destination order kept", dest: "https://example.com/p?b=2&a=1", u: both, query: "c=3", want: "https://example.com/p?b=2&a=1&c=3"},
+		{name: "malformed pairs dropped", dest: "https://example.com/p", u: both, query: "a=%zz&b=2", want: "https://example.com/p?b=2"},
+		{name: "fragment kept", dest: "https://example.com/p#top", u: both, suffix: "x", query: "a=1", want: "https://example.com/p/x?a=1#top"},
+		{name: "path only", dest: "https://example.com/p", u: &domain.URL{PathPassthrough: true}, suffix: "x", query: "a=1", want: "https://example.com/p/x"},
+		{name: "query only", dest: "https://example.com/p", u: &domain.URL{ForwardQuery: true}, suffix: "x", query: "a=1", want: "https://example.com/p?a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPassthrough(tt.dest, tt.u, dto.RedirectCommand{PathSuffix: tt.suffix, RawQuery: tt.query})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("applyPassthrough error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("applyPassthrough = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
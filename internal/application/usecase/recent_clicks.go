@@ -10,17 +10,21 @@ import (
 )
 
 func (uc *URLShortenerUseCase) GetRecentClicks(ctx context.Context, query dto.RecentClicksQuery) (dto.RecentClicksResult, error) {
+	query.Short = domain.NormalizeShortCode(query.Short)
 	if query.Short == "" {
 		return dto.RecentClicksResult{}, ErrShortCodeRequired
 	}
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return dto.RecentClicksResult{}, err
+	}
 
-	u, err := uc.repo.FindByShort(ctx, query.Short)
+	u, err := uc.findManaged(ctx, scope, query.Domain, query.Short)
 	if err != nil {
-		zlog.Logger.Error().Err(err).Msg("repo error in recent clicks")
-		return dto.RecentClicksResult{}, ErrNotFound
+		return dto.RecentClicksResult{}, err
 	}
-	if u == nil {
-		return dto.RecentClicksResult{}, ErrNotFound
+	if err := requireAccess(query.Actor, u); err != nil {
+		return dto.RecentClicksResult{}, err
 	}
 
 	limit := query.Limit
@@ -28,7 +32,7 @@ func (uc *URLShortenerUseCase) GetRecentClicks(ctx context.Context, query dto.Re
 		limit = 50
 	}
 
-	clicks, err := uc.repo.GetRecentClicks(ctx, query.Short, limit)
+	clicks, err := uc.repo.GetRecentClicks(ctx, u.ID, limit)
 	if err != nil {
 		zlog.Logger.Error().Err(err).Msg("failed to get recent clicks")
 		return dto.RecentClicksResult{}, fmt.Errorf("failed to get recent clicks")
@@ -48,6 +52,7 @@ func (uc *URLShortenerUseCase) GetRecentClicks(ctx context.Context, query dto.Re
 			"ip":          click.IP,
 			"referrer":    click.Referrer,
 			"device":      click.Device,
+			"variant":     click.Variant,
 		}
 	}
 
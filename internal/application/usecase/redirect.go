@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/wb-go/wbf/zlog"
@@ -9,39 +10,155 @@ import (
 	"github.com/yokitheyo/URLShortener/internal/domain"
 )
 
-func (uc *URLShortenerUseCase) Redirect(ctx context.Context, short string, meta dto.ClickMetadata) (string, error) {
-	if short == "" {
-		return "", ErrShortCodeRequired
+func (uc *URLShortenerUseCase) Redirect(ctx context.Context, cmd dto.RedirectCommand) (dto.RedirectResult, error) {
+	cmd.Short = domain.NormalizeShortCode(cmd.Short)
+	if cmd.Short == "" {
+		return dto.RedirectResult{}, ErrShortCodeRequired
 	}
 
-	urlObj, err := uc.repo.FindByShort(ctx, short)
+	code, signed, err := uc.openShort(cmd.Short)
 	if err != nil {
-		zlog.Logger.Warn().Err(err).Str("short", short).Msg("repo error finding URL")
-		return "", ErrNotFound
+		return dto.RedirectResult{}, err
 	}
-	if urlObj == nil {
-		return "", ErrNotFound
+	domainID, err := uc.visitorDomain(ctx, cmd.Host)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Str("host", cmd.Host).Msg("repo error finding domain")
+		return dto.RedirectResult{}, ErrNotFound
+	}
+
+	urlObj, err := uc.repo.FindByShort(ctx, domain.AllWorkspaces, domainID, code)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Str("short", code).Msg("repo error finding URL")
+		return dto.RedirectResult{}, ErrNotFound
+	}
+	if !reachable(urlObj, signed) {
+		return dto.RedirectResult{}, ErrNotFound
+	}
+	if urlObj.IsDisabled {
+		return uc.inactiveRedirect(urlObj, ErrDisabled)
+	}
+	if urlObj.IsExpired(time.Now()) {
+		return uc.inactiveRedirect(urlObj, ErrExpired)
+	}
+	if cmd.PathSuffix != "" && !urlObj.PathPassthrough {
+		return dto.RedirectResult{}, ErrNotFound
+	}
+
+	urlObj.Variants, err = uc.repo.GetVariants(ctx, urlObj.ID)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Str("short", cmd.Short).Msg("failed to load variants")
+	}
+
+	result := dto.RedirectResult{
+		URL:        urlObj.Original,
+		StatusCode: uc.redirectCode(urlObj),
+		ExpiresAt:  urlObj.ExpiresAt,
+	}
+	variant := selectVariant(urlObj, cmd)
+	if variant != nil {
+		result.URL = variant.Destination
+		result.Variant = variant.Label
+		result.RememberVariant = urlObj.StickyMode == domain.StickyCookie
+	}
+	result.Cacheable = variant == nil
+
+	result.URL, err = applyPassthrough(result.URL, urlObj, cmd)
+	if err != nil {
+		return dto.RedirectResult{}, err
+	}
+
+	result.URL, err = applyUTM(result.URL, urlObj)
+	if err != nil {
+		return dto.RedirectResult{}, err
+	}
+
+	if uc.useFallback(urlObj, time.Now()) {
+		result.URL = urlObj.FallbackURL
+		result.Fallback = true
+		result.Cacheable = false
+	}
+
+	if urlObj.RiskFlag != "" && !cmd.Proceed {
+		result.Interstitial = true
+		result.RiskFlag = urlObj.RiskFlag
+		result.ProceedTicket = uc.proceedTicket(cmd.Host, cmd.Short, time.Now())
+		if !cmd.SkipClick {
+			if err := uc.repo.IncrementWarningsShown(ctx, urlObj.ID); err != nil {
+				zlog.Logger.Warn().Err(err).Msg("failed to count warning view")
+			}
+		}
+		return result, nil
+	}
+
+	if cmd.SkipClick {
+		return result, nil
 	}
 
 	if err := uc.repo.IncrementVisits(ctx, urlObj.ID); err != nil {
 		zlog.Logger.Warn().Err(err).Msg("failed to increment visits")
 	}
 
-	location := uc.resolveLocation(meta.IP)
+	location := uc.resolveLocation(cmd.Meta.IP)
 
 	click := &domain.Click{
 		URLID:      urlObj.ID,
 		Short:      urlObj.Short,
 		OccurredAt: time.Now(),
-		UserAgent:  meta.UserAgent,
+		UserAgent:  cmd.Meta.UserAgent,
 		IP:         location,
-		Referrer:   meta.Browser,
-		Device:     meta.Device,
+		Referrer:   cmd.Meta.Browser,
+		Device:     cmd.Meta.Device,
+
+		WorkspaceID: urlObj.WorkspaceID,
+	}
+	if variant != nil {
+		click.VariantID = variant.ID
+		click.Variant = variant.Label
 	}
 
 	if err := uc.repo.SaveClick(ctx, click); err != nil {
 		zlog.Logger.Warn().Err(err).Msg("failed to save click")
 	}
+	// a custom domain is only found by the host it is served on
+	linkHost := ""
+	if domainID != domain.DefaultDomain {
+		linkHost = domain.NormalizeHost(cmd.Host)
+	}
+	enqueueWebhooks(ctx, uc.webhooks, domain.EventLinkClicked, urlObj, uc.servedHost(linkHost), click)
+
+	return result, nil
+}
+
+// inactiveRedirect sends visitors of an expired or disabled link to its fallback,
+// or the global default one, and returns reason when there is neither
+func (uc *URLShortenerUseCase) inactiveRedirect(u *domain.URL, reason error) (dto.RedirectResult, error) {
+	fallback := u.FallbackURL
+	if fallback == "" {
+		fallback = uc.settings.DefaultFallbackURL
+	}
+	if fallback == "" {
+		return dto.RedirectResult{}, reason
+	}
+
+	return dto.RedirectResult{
+		URL:        fallback,
+		StatusCode: http.StatusFound,
+		Fallback:   true,
+	}, nil
+}
+
+// useFallback reports whether visitors should go to the link's fallback
+// because its destination has been failing health checks for too long
+func (uc *URLShortenerUseCase) useFallback(u *domain.URL, now time.Time) bool {
+	if uc.settings.HealthFallbackAfter <= 0 || u.FallbackURL == "" {
+		return false
+	}
+	return u.Health.IsBroken(uc.settings.HealthFallbackAfter, now)
+}
 
-	return urlObj.Original, nil
+func (uc *URLShortenerUseCase) redirectCode(u *domain.URL) int {
+	if domain.IsValidRedirectCode(u.RedirectCode) {
+		return u.RedirectCode
+	}
+	return uc.settings.DefaultRedirectCode
 }
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+var reservedWordRegex = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)
+
+// ListReservedWords returns every word that cannot be used as a short code
+func (uc *URLShortenerUseCase) ListReservedWords(ctx context.Context, actor domain.Actor) ([]domain.ReservedWord, error) {
+	if err := requireAdmin(actor); err != nil {
+		return nil, err
+	}
+	words, err := uc.codeFilter.List(ctx)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list reserved words")
+		return nil, fmt.Errorf("failed to list reserved words")
+	}
+	return words, nil
+}
+
+func (uc *URLShortenerUseCase) AddReservedWord(ctx context.Context, actor domain.Actor, word string) error {
+	if err := requireAdmin(actor); err != nil {
+		return err
+	}
+	word, err := uc.normalizeReservedWord(word)
+	if err != nil {
+		return err
+	}
+	if _, ok := uc.codeFilter.builtinSource(word); ok {
+		return ErrReservedWordExists
+	}
+
+	added, err := uc.reservedWords.Add(ctx, word)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("word", word).Msg("failed to add reserved word")
+		return fmt.Errorf("failed to add reserved word")
+	}
+	if !added {
+		return ErrReservedWordExists
+	}
+	return nil
+}
+
+// RemoveReservedWord drops an admin-managed word, route and config words stay
+func (uc *URLShortenerUseCase) RemoveReservedWord(ctx context.Context, actor domain.Actor, word string) error {
+	if err := requireAdmin(actor); err != nil {
+		return err
+	}
+	word, err := uc.normalizeReservedWord(word)
+	if err != nil {
+		return err
+	}
+	if source, ok := uc.codeFilter.builtinSource(word); ok {
+		return fmt.Errorf("%w: reserved by %s", ErrReservedWordBuiltin, source)
+	}
+
+	removed, err := uc.reservedWords.Remove(ctx, word)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("word", word).Msg("failed to remove reserved word")
+		return fmt.Errorf("failed to remove reserved word")
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (uc *URLShortenerUseCase) normalizeReservedWord(word string) (string, error) {
+	if uc.reservedWords == nil {
+		return "", fmt.Errorf("reserved words are not configured")
+	}
+	word = strings.ToLower(domain.NormalizeShortCode(strings.TrimSpace(word)))
+	if !domain.IsShortCodeLength(word) || !reservedWordRegex.MatchString(word) {
+		return "", fmt.Errorf("%w: use letters, digits, _ and -, up to %d characters", ErrInvalidReservedWord, domain.MaxShortLength)
+	}
+	return word, nil
+}
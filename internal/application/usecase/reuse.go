@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+)
+
+// destinationHash identifies a destination for link reuse by its canonical form
+func destinationHash(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// wantsReuse reports whether cmd may be answered with an existing link. Requests
+// with a custom code or any per-link option always get a new link.
+func (uc *URLShortenerUseCase) wantsReuse(cmd dto.ShortenCommand) bool {
+	reuse := uc.settings.ReuseExisting
+	if cmd.ReuseExisting != nil {
+		reuse = *cmd.ReuseExisting
+	}
+	if !reuse {
+		return false
+	}
+
+	return cmd.Custom == "" &&
+		!cmd.Signed &&
+		len(cmd.Variants) == 0 &&
+		!cmd.ForwardQuery &&
+		!cmd.PathPassthrough &&
+		cmd.UTM == (dto.UTMSpec{}) &&
+		cmd.RedirectCode == 0 &&
+		cmd.FallbackURL == ""
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// reuseRepo stores created links and looks up reusable ones with the
+// postgres repository's reuse key
+type reuseRepo struct {
+	ports.URLRepository
+	links []*domain.URL
+}
+
+func (r *reuseRepo) Create(_ context.Context, u *domain.URL, _ *domain.AuditEvent) error {
+	c := *u
+	r.links = append(r.links, &c)
+	return nil
+}
+
+func (r *reuseRepo) FindReusable(_ context.Context, workspaceID, ownerID, domainID int64, hash string, minExpiresAt time.Time) (*domain.URL, error) {
+	for _, u := range r.links {
+		if u.OriginalHash != hash || u.WorkspaceID != workspaceID || u.DomainID != domainID || u.IsCustom {
+			continue
+		}
+		if workspaceID == 0 && u.OwnerID != ownerID {
+			continue
+		}
+		if u.ExpiresAt.Before(minExpiresAt) {
+			continue
+		}
+		return u, nil
+	}
+	return nil, nil
+}
+
+type nopCache struct{ ports.Cache }
+
+func (nopCache) Set(context.Context, string, string, time.Duration) error { return nil }
+
+func TestShortenReusesOnlyLinksTheCallerControls(t *testing.T) {
+	personalA := domain.Actor{UserID: 1, Admin: true}
+	personalB := domain.Actor{UserID: 2, Admin: true}
+	editorA := domain.Actor{UserID: 1}.InWorkspace(3, domain.RoleEditor)
+	editorB := domain.Actor{UserID: 2}.InWorkspace(3, domain.RoleEditor)
+	otherWorkspace := domain.Actor{UserID: 2}.InWorkspace(4, domain.RoleEditor)
+
+	tests := []struct {
+		name        string
+		first, then domain.Actor
+		wantReused  bool
+	}{
+		{name: "same owner", first: personalA, then: personalA, wantReused: true},
+		{name: "other owner", first: personalA, then: personalB},
+		{name: "anonymous callers", first: domain.Actor{}, then: domain.Actor{}, wantReused: true},
+		{name: "anonymous after owner", first: personalA, then: domain.Actor{}},
+		{name: "same workspace", first: editorA, then: editorB, wantReused: true},
+		{name: "other workspace", first: editorA, then: otherWorkspace},
+		{name: "workspace link for personal caller", first: editorA, then: personalA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewURLShortenerUseCase(&reuseRepo{}, nopCache{}, nil).
+				WithSettings(Settings{ReuseExisting: true, AllowAnonymous: true})
+
+			first, err := uc.Shorten(context.Background(), dto.ShortenCommand{Actor: tt.first, URL: "https://example.com/page"})
+			if err != nil {
+				t.Fatalf("first Shorten: %v", err)
+			}
+			then, err := uc.Shorten(context.Background(), dto.ShortenCommand{Actor: tt.then, URL: "https://example.com/page"})
+			if err != nil {
+				t.Fatalf("second Shorten: %v", err)
+			}
+
+			if then.Reused != tt.wantReused {
+				t.Errorf("Reused = %v, want %v", then.Reused, tt.wantReused)
+			}
+			if sameCode := then.Short == first.Short; sameCode != tt.wantReused {
+				t.Errorf("second code %q, first %q: shared = %v, want %v", then.Short, first.Short, sameCode, tt.wantReused)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// proceedTicketTTL is how long a warning page can be confirmed after it was shown
+const proceedTicketTTL = 10 * time.Minute
+
+var riskFlagRegex = regexp.MustCompile(`^[a-z_]{1,32}$`)
+
+// SetRiskFlag lets admins put a link behind the warning page or take it off
+func (uc *URLShortenerUseCase) SetRiskFlag(ctx context.Context, cmd dto.SetRiskFlagCommand) error {
+	if err := requireAdmin(cmd.Actor); err != nil {
+		return err
+	}
+	cmd.Short = domain.NormalizeShortCode(cmd.Short)
+	if cmd.Short == "" {
+		return ErrShortCodeRequired
+	}
+	if cmd.Flag != "" && !riskFlagRegex.MatchString(cmd.Flag) {
+		return fmt.Errorf("%w: use lowercase letters and underscores, up to 32 characters", ErrInvalidRiskFlag)
+	}
+
+	scope, err := workspaceScope(cmd.Actor)
+	if err != nil {
+		return err
+	}
+
+	u, err := uc.findManaged(ctx, scope, cmd.Domain, cmd.Short)
+	if err != nil {
+		return err
+	}
+
+	before := *u
+	u.RiskFlag = cmd.Flag
+	found, err := uc.repo.Update(ctx, u, newAuditEvent(domain.AuditLinkFlagged, cmd.Actor, cmd.Request, &before, u))
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("short", cmd.Short).Msg("failed to set risk flag")
+		return fmt.Errorf("failed to set risk flag")
+	}
+	if !found {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ConfirmWarning records that a visitor on host chose to continue past the warning
+// page. Like Redirect it serves visitors, who have no account, so it checks no workspace.
+// ticket is the one Redirect issued with the page, without it nothing is counted.
+func (uc *URLShortenerUseCase) ConfirmWarning(ctx context.Context, host, short, ticket string) error {
+	short = domain.NormalizeShortCode(short)
+	if short == "" {
+		return ErrShortCodeRequired
+	}
+	if !uc.validProceedTicket(ticket, host, short, time.Now()) {
+		return ErrNoWarningShown
+	}
+
+	code, signed, err := uc.openShort(short)
+	if err != nil {
+		return err
+	}
+
+	domainID, err := uc.visitorDomain(ctx, host)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("repo error finding domain")
+		return ErrNotFound
+	}
+
+	u, err := uc.repo.FindByShort(ctx, domain.AllWorkspaces, domainID, code)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Str("short", code).Msg("repo error confirming warning")
+		return ErrNotFound
+	}
+	if !reachable(u, signed) {
+		return ErrNotFound
+	}
+	if u.RiskFlag == "" {
+		return nil
+	}
+
+	if err := uc.repo.IncrementWarningsProceeded(ctx, u.ID); err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to count warning proceed")
+	}
+	return nil
+}
+
+// proceedTicket proves that the warning page of short was shown on host. It is
+// expiry.signature, an HMAC-SHA256 of host, short and expiry under warningKey.
+func (uc *URLShortenerUseCase) proceedTicket(host, short string, now time.Time) string {
+	expiry := strconv.FormatInt(now.Add(proceedTicketTTL).Unix(), 10)
+	return expiry + "." + uc.proceedSignature(host, short, expiry)
+}
+
+func (uc *URLShortenerUseCase) validProceedTicket(ticket, host, short string, now time.Time) bool {
+	expiry, sig, ok := strings.Cut(ticket, ".")
+	if !ok {
+		return false
+	}
+	if !hmac.Equal([]byte(sig), []byte(uc.proceedSignature(host, short, expiry))) {
+		return false
+	}
+	exp, err := strconv.ParseInt(expiry, 10, 64)
+	return err == nil && now.Before(time.Unix(exp, 0))
+}
+
+func (uc *URLShortenerUseCase) proceedSignature(host, short, expiry string) string {
+	mac := hmac.New(sha256.New, uc.warningKey)
+	mac.Write([]byte(domain.NormalizeHost(host) + "\n" + short + "\n" + expiry))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
+
+func randomWarningKey() []byte {
+	key := make([]byte, minSigningKeyBytes)
+	rand.Read(key)
+	return key
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// warningRepo holds one flagged link and counts its warning page
+type warningRepo struct {
+	ports.URLRepository
+	link      *domain.URL
+	shown     int
+	proceeded int
+}
+
+func (r *warningRepo) FindByShort(_ context.Context, _, _ int64, short string) (*domain.URL, error) {
+	if short != r.link.Short {
+		return nil, nil
+	}
+	return r.link, nil
+}
+
+func (r *warningRepo) GetVariants(context.Context, int64) ([]domain.Variant, error) { return nil, nil }
+
+func (r *warningRepo) IncrementWarningsShown(context.Context, int64) error {
+	r.shown++
+	return nil
+}
+
+func (r *warningRepo) IncrementWarningsProceeded(context.Context, int64) error {
+	r.proceeded++
+	return nil
+}
+
+func TestConfirmWarningNeedsTicket(t *testing.T) {
+	const host = "sho.example"
+	repo := &warningRepo{link: &domain.URL{ID: 1, Short: "Xk3fQa", Original: "https://example.com/setup.exe", RiskFlag: "executable_download"}}
+	uc := NewURLShortenerUseCase(repo, nil, nil).WithWarningSecret(strings.Repeat("w", minSigningKeyBytes))
+
+	result, err := uc.Redirect(context.Background(), dto.RedirectCommand{Host: host, Short: "Xk3fQa", SkipClick: true})
+	if err != nil {
+		t.Fatalf("Redirect: %v", err)
+	}
+	if !result.Interstitial || result.ProceedTicket == "" {
+		t.Fatalf("Redirect = %+v, want the warning page with a ticket", result)
+	}
+	ticket := result.ProceedTicket
+	expiry, sig, _ := strings.Cut(ticket, ".")
+	other := NewURLShortenerUseCase(repo, nil, nil)
+
+	tests := []struct {
+		name    string
+		uc      *URLShortenerUseCase
+		host    string
+		short   string
+		ticket  string
+		wantErr error
+	}{
+		{name: "shown page", uc: uc, host: host, short: "Xk3fQa", ticket: ticket},
+		{name: "host case ignored", uc: uc, host: "SHO.example", short: "Xk3fQa", ticket: ticket},
+		{name: "no ticket", uc: uc, host: host, short: "Xk3fQa", wantErr: ErrNoWarningShown},
+		{name: "other link", uc: uc, host: host, short: "Ab12Cd", ticket: ticket, wantErr: ErrNoWarningShown},
+		{name: "other host", uc: uc, host: "go.example", short: "Xk3fQa", ticket: ticket, wantErr: ErrNoWarningShown},
+		{name: "extended expiry", uc: uc, host: host, short: "Xk3fQa", ticket: "9999999999." + sig, wantErr: ErrNoWarningShown},
+		{name: "forged signature", uc: uc, host: host, short: "Xk3fQa", ticket: expiry + "." + strings.Repeat("A", len(sig)), wantErr: ErrNoWarningShown},
+		{name: "expired", uc: uc, host: host, short: "Xk3fQa", ticket: uc.proceedTicket(host, "Xk3fQa", time.Now().Add(-proceedTicketTTL)), wantErr: ErrNoWarningShown},
+		{name: "other key", uc: other, host: host, short: "Xk3fQa", ticket: ticket, wantErr: ErrNoWarningShown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := repo.proceeded
+			err := tt.uc.ConfirmWarning(context.Background(), tt.host, tt.short, tt.ticket)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmWarning error = %v, want %v", err, tt.wantErr)
+			}
+			if counted := repo.proceeded - before; counted != 0 && tt.wantErr != nil {
+				t.Errorf("counted %d confirmations without a valid ticket", counted)
+			} else if counted != 1 && tt.wantErr == nil {
+				t.Errorf("counted %d confirmations, want 1", counted)
+			}
+		})
+	}
+}
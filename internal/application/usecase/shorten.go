@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,18 +12,119 @@ import (
 )
 
 func (uc *URLShortenerUseCase) Shorten(ctx context.Context, cmd dto.ShortenCommand) (dto.ShortenResult, error) {
+	if cmd.Actor.IsAnonymous() {
+		if !uc.settings.AllowAnonymous {
+			return dto.ShortenResult{}, ErrUnauthorized
+		}
+	} else if err := requireWriter(cmd.Actor); err != nil {
+		return dto.ShortenResult{}, err
+	}
 	if cmd.URL == "" {
 		return dto.ShortenResult{}, ErrURLRequired
 	}
+	if cmd.Signed && uc.signer == nil {
+		return dto.ShortenResult{}, ErrSigningDisabled
+	}
+
+	original, target, err := uc.resolveDestination(ctx, cmd.URL)
+	if err != nil {
+		return dto.ShortenResult{}, err
+	}
+	riskFlag := uc.policy.Assess(target)
+	canonical := uc.canonicalizer.Canonicalize(target)
+	hash := destinationHash(canonical)
 
-	short := cmd.Custom
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if cmd.Expires > 0 {
+		expiresAt = time.Unix(cmd.Expires, 0)
+	}
+
+	variants, err := buildVariants(cmd.Variants)
+	if err != nil {
+		return dto.ShortenResult{}, err
+	}
+	for i := range variants {
+		v := &variants[i]
+		dest, variantTarget, err := uc.resolveDestination(ctx, v.Destination)
+		if err != nil {
+			return dto.ShortenResult{}, fmt.Errorf("variant %q: %w", v.Label, err)
+		}
+		v.Destination = dest
+		if riskFlag == "" {
+			riskFlag = uc.policy.Assess(variantTarget)
+		}
+	}
+
+	stickyMode, err := normalizeStickyMode(cmd.StickyMode)
+	if err != nil {
+		return dto.ShortenResult{}, err
+	}
+
+	utm, utmMode, err := buildUTM(cmd.UTM, cmd.UTMMode)
+	if err != nil {
+		return dto.ShortenResult{}, err
+	}
+
+	if cmd.RedirectCode != 0 && !domain.IsValidRedirectCode(cmd.RedirectCode) {
+		return dto.ShortenResult{}, ErrInvalidRedirectCode
+	}
+
+	var fallback string
+	if cmd.FallbackURL != "" {
+		target, err := uc.policy.Check(ctx, cmd.FallbackURL)
+		if err != nil {
+			return dto.ShortenResult{}, fmt.Errorf("fallback: %w", err)
+		}
+		fallback = target.String()
+	}
+
+	var customDomain *domain.CustomDomain
+	domainID := domain.DefaultDomain
+	if cmd.Domain != "" {
+		if cmd.Actor.WorkspaceID == 0 {
+			return dto.ShortenResult{}, fmt.Errorf("%w: pick the workspace with the X-Workspace-ID header", ErrInvalidDomain)
+		}
+		customDomain, err = uc.managedDomain(ctx, cmd.Actor.WorkspaceID, cmd.Domain)
+		if err != nil {
+			return dto.ShortenResult{}, err
+		}
+		if !customDomain.Verified() {
+			return dto.ShortenResult{}, fmt.Errorf("%w: verify %s first", ErrDomainNotVerified, customDomain.Host)
+		}
+		domainID = customDomain.ID
+	}
+
+	if uc.wantsReuse(cmd) {
+		minExpiresAt := time.Now()
+		if cmd.Expires > 0 {
+			minExpiresAt = expiresAt
+		}
+
+		existing, err := uc.repo.FindReusable(ctx, cmd.Actor.WorkspaceID, cmd.Actor.UserID, domainID, hash, minExpiresAt)
+		if err != nil {
+			zlog.Logger.Warn().Err(err).Msg("failed to look up reusable link")
+		} else if existing != nil {
+			return dto.ShortenResult{
+				Short:     existing.Short,
+				Domain:    domainHost(customDomain),
+				ExpiresAt: existing.ExpiresAt,
+				Reused:    true,
+			}, nil
+		}
+	}
+
+	short := domain.NormalizeShortCode(cmd.Custom)
 
 	if short != "" {
 		if err := uc.validator.ValidateCustomShort(short); err != nil {
-			return dto.ShortenResult{}, ErrInvalidCustomShort
+			return dto.ShortenResult{}, fmt.Errorf("%w: %v", ErrInvalidCustomShort, err)
+		}
+		if err := uc.codeFilter.Check(ctx, short, false); err != nil {
+			return dto.ShortenResult{}, err
 		}
 
-		existing, err := uc.repo.FindByShort(ctx, short)
+		// codes are unique across workspaces, within a domain
+		existing, err := uc.repo.FindByShort(ctx, domain.AllWorkspaces, domainID, short)
 		if err != nil {
 			zlog.Logger.Error().Err(err).Msg("repo error checking custom short")
 			return dto.ShortenResult{}, fmt.Errorf("database error")
@@ -30,39 +132,62 @@ func (uc *URLShortenerUseCase) Shorten(ctx context.Context, cmd dto.ShortenComma
 		if existing != nil {
 			return dto.ShortenResult{}, ErrShortCodeAlreadyExists
 		}
-	} else {
-		var err error
-		short, err = GenerateShortCode()
-		if err != nil {
-			zlog.Logger.Error().Err(err).Msg("failed to generate short code")
-			return dto.ShortenResult{}, fmt.Errorf("failed to generate short code")
-		}
-	}
-
-	expiresAt := time.Now().Add(24 * time.Hour)
-	if cmd.Expires > 0 {
-		expiresAt = time.Unix(cmd.Expires, 0)
 	}
 
 	url := &domain.URL{
-		Short:     short,
-		Original:  cmd.URL,
-		CreatedAt: time.Now(),
-		ExpiresAt: expiresAt,
-		Visits:    0,
+		Short:      short,
+		Original:   original,
+		Canonical:  canonical,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+		Visits:     0,
+		StickyMode: stickyMode,
+		Variants:   variants,
+
+		ForwardQuery:    cmd.ForwardQuery,
+		PathPassthrough: cmd.PathPassthrough,
+
+		UTM:     utm,
+		UTMMode: utmMode,
+
+		RedirectCode: cmd.RedirectCode,
+		RiskFlag:     riskFlag,
+		FallbackURL:  fallback,
+
+		OriginalHash: hash,
+		IsCustom:     cmd.Custom != "",
+		Signed:       cmd.Signed,
+
+		OwnerID:     cmd.Actor.UserID,
+		WorkspaceID: cmd.Actor.WorkspaceID,
+		DomainID:    domainID,
 	}
 
-	if err := uc.repo.Create(ctx, url); err != nil {
+	event := newAuditEvent(domain.AuditLinkCreated, cmd.Actor, cmd.Request, nil, url)
+	if short == "" {
+		if err := uc.createWithGeneratedCode(ctx, url, event); err != nil {
+			return dto.ShortenResult{}, err
+		}
+		short = url.Short
+	} else if err := uc.repo.Create(ctx, url, event); err != nil {
+		if errors.Is(err, domain.ErrShortConflict) {
+			return dto.ShortenResult{}, ErrShortCodeAlreadyExists
+		}
 		zlog.Logger.Error().Err(err).Msg("failed to save URL")
 		return dto.ShortenResult{}, fmt.Errorf("failed to save URL")
 	}
 
-	if err := uc.cache.Set(ctx, short, cmd.URL, time.Until(expiresAt)); err != nil {
+	if err := uc.cache.Set(ctx, cacheKey(url), url.Original, time.Until(expiresAt)); err != nil {
 		zlog.Logger.Warn().Err(err).Str("short", short).Msg("failed to cache URL")
 	}
+	enqueueWebhooks(ctx, uc.webhooks, domain.EventLinkCreated, url, uc.servedHost(domainHost(customDomain)), nil)
 
+	if url.Signed {
+		short = uc.signer.Sign(short, expiresAt)
+	}
 	return dto.ShortenResult{
 		Short:     short,
+		Domain:    domainHost(customDomain),
 		ExpiresAt: expiresAt,
 	}, nil
 }
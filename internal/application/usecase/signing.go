@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	// signedSeparator joins the parts of a token, short codes never contain it
+	signedSeparator    = "~"
+	signatureBytes     = 16
+	minSigningKeyBytes = 32
+)
+
+var signingKeyIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,16}$`)
+
+// SigningKey is one key of the link signing key set
+type SigningKey struct {
+	ID     string
+	Secret string
+}
+
+// LinkSigner issues and verifies signed link tokens of the form
+// code~expiry~kid~signature, where the signature is an HMAC-SHA256 of the
+// rest of the token under key kid. Every key in the set verifies, only the
+// active one signs, so keys are rotated by adding a key, making it active and
+// removing the old one once its tokens have expired.
+type LinkSigner struct {
+	keys   map[string][]byte
+	active string
+}
+
+func NewLinkSigner(keys []SigningKey, active string) (*LinkSigner, error) {
+	s := &LinkSigner{keys: make(map[string][]byte, len(keys)), active: active}
+	for _, k := range keys {
+		if !signingKeyIDRegex.MatchString(k.ID) {
+			return nil, fmt.Errorf("signing key id %q must be 1 to 16 letters, digits, dashes or underscores", k.ID)
+		}
+		if len(k.Secret) < minSigningKeyBytes {
+			return nil, fmt.Errorf("signing key %q must be at least %d bytes", k.ID, minSigningKeyBytes)
+		}
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, fmt.Errorf("signing key %q is listed twice", k.ID)
+		}
+		s.keys[k.ID] = []byte(k.Secret)
+	}
+	if _, ok := s.keys[active]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not in the key set", active)
+	}
+	return s, nil
+}
+
+// IsSignedToken reports whether short is a token rather than a bare code
+func IsSignedToken(short string) bool {
+	return strings.Contains(short, signedSeparator)
+}
+
+// Sign returns the token for code, valid until expiresAt
+func (s *LinkSigner) Sign(code string, expiresAt time.Time) string {
+	payload := code + signedSeparator + strconv.FormatInt(expiresAt.Unix(), 10) + signedSeparator + s.active
+	return payload + signedSeparator + s.signature(s.keys[s.active], payload)
+}
+
+// Verify checks token without touching storage and returns its code.
+// Malformed tokens, unknown keys and bad signatures are ErrNotFound, so they
+// tell nothing about the codes that exist. Tokens past their expiry are
+// ErrExpired.
+func (s *LinkSigner) Verify(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, signedSeparator)
+	if len(parts) != 4 || parts[0] == "" {
+		return "", ErrNotFound
+	}
+	code, expiry, kid, sig := parts[0], parts[1], parts[2], parts[3]
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return "", ErrNotFound
+	}
+	want := s.signature(key, strings.Join(parts[:3], signedSeparator))
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return "", ErrNotFound
+	}
+
+	exp, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	if !now.Before(time.Unix(exp, 0)) {
+		return "", ErrExpired
+	}
+	return code, nil
+}
+
+func (s *LinkSigner) signature(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
+
+// openShort turns what a visitor brought, a bare code or a signed token, into
+// the code to look up. Tokens are verified here, before any storage is read.
+func (uc *URLShortenerUseCase) openShort(short string) (code string, signed bool, err error) {
+	if !IsSignedToken(short) {
+		return short, false, nil
+	}
+	if uc.signer == nil {
+		return "", false, ErrNotFound
+	}
+	code, err = uc.signer.Verify(short, time.Now())
+	if err != nil {
+		return "", false, err
+	}
+	return code, true, nil
+}
+
+// reachable reports whether u may be opened the way it was asked for: signed
+// links only through a token, the others only by their code
+func reachable(u *domain.URL, signed bool) bool {
+	return u != nil && u.Signed == signed
+}
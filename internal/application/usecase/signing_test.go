@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+var (
+	oldKey = SigningKey{ID: "2025-09", Secret: strings.Repeat("o", minSigningKeyBytes)}
+	newKey = SigningKey{ID: "2025-10", Secret: strings.Repeat("n", minSigningKeyBytes)}
+)
+
+func newTestSigner(t *testing.T, active string, keys ...SigningKey) *LinkSigner {
+	t.Helper()
+	s, err := NewLinkSigner(keys, active)
+	if err != nil {
+		t.Fatalf("NewLinkSigner: %v", err)
+	}
+	return s
+}
+
+// replacePart swaps part i of a token, keeping the rest as signed
+func replacePart(token string, i int, value string) string {
+	parts := strings.Split(token, signedSeparator)
+	parts[i] = value
+	return strings.Join(parts, signedSeparator)
+}
+
+func TestLinkSignerVerify(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	expires := now.Add(time.Hour)
+	signer := newTestSigner(t, newKey.ID, oldKey, newKey)
+	token := signer.Sign("Xk3fQa", expires)
+	sig := strings.Split(token, signedSeparator)[3]
+
+	tests := []struct {
+		name    string
+		token   string
+		now     time.Time
+		want    string
+		wantErr error
+	}{
+		{name: "valid", token: token, now: now, want: "Xk3fQa"},
+		{name: "tampered code", token: replacePart(token, 0, "Xk3fQb"), now: now, wantErr: ErrNotFound},
+		{name: "tampered expiry", token: replacePart(token, 1, "1860000000"), now: now, wantErr: ErrNotFound},
+		{name: "tampered kid", token: replacePart(token, 2, oldKey.ID), now: now, wantErr: ErrNotFound},
+		{name: "tampered signature", token: replacePart(token, 3, strings.Repeat("A", len(sig))), now: now, wantErr: ErrNotFound},
+		{name: "truncated signature", token: replacePart(token, 3, sig[:len(sig)-1]), now: now, wantErr: ErrNotFound},
+		{name: "unknown kid", token: newTestSigner(t, "other", SigningKey{ID: "other", Secret: newKey.Secret}).Sign("Xk3fQa", expires), now: now, wantErr: ErrNotFound},
+		{name: "extra part", token: token + signedSeparator + "x", now: now, wantErr: ErrNotFound},
+		{name: "missing code", token: replacePart(token, 0, ""), now: now, wantErr: ErrNotFound},
+		{name: "bare code", token: "Xk3fQa", now: now, wantErr: ErrNotFound},
+		{name: "expired", token: token, now: expires, wantErr: ErrExpired},
+		{name: "long expired", token: token, now: expires.Add(24 * time.Hour), wantErr: ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signer.Verify(tt.token, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkSignerRotation(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	expires := now.Add(time.Hour)
+	before := newTestSigner(t, oldKey.ID, oldKey)
+	during := newTestSigner(t, newKey.ID, oldKey, newKey)
+	after := newTestSigner(t, newKey.ID, newKey)
+
+	oldToken := before.Sign("Xk3fQa", expires)
+	if code, err := during.Verify(oldToken, now); err != nil || code != "Xk3fQa" {
+		t.Errorf("token of the old key: Verify = %q, %v, want it to still verify", code, err)
+	}
+
+	newToken := during.Sign("Xk3fQa", expires)
+	if kid := strings.Split(newToken, signedSeparator)[2]; kid != newKey.ID {
+		t.Errorf("signed with %q, want the active key %q", kid, newKey.ID)
+	}
+	if _, err := after.Verify(newToken, now); err != nil {
+		t.Errorf("token of the new key after rotation: %v", err)
+	}
+	if _, err := after.Verify(oldToken, now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("token of a removed key: Verify error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNewLinkSignerRejectsBadKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []SigningKey
+		active string
+	}{
+		{name: "short secret", keys: []SigningKey{{ID: "k1", Secret: "short"}}, active: "k1"},
+		{name: "bad id", keys: []SigningKey{{ID: "k~1", Secret: newKey.Secret}}, active: "k~1"},
+		{name: "duplicate id", keys: []SigningKey{newKey, newKey}, active: newKey.ID},
+		{name: "active not in set", keys: []SigningKey{newKey}, active: oldKey.ID},
+		{name: "no keys", active: newKey.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLinkSigner(tt.keys, tt.active); err == nil {
+				t.Error("NewLinkSigner accepted the key set")
+			}
+		})
+	}
+}
+
+func TestOpenShort(t *testing.T) {
+	signer := newTestSigner(t, newKey.ID, newKey)
+	uc := NewURLShortenerUseCase(nil, nil, nil).WithLinkSigner(signer)
+	token := signer.Sign("Xk3fQa", time.Now().Add(time.Hour))
+
+	if code, signed, err := uc.openShort("Xk3fQa"); err != nil || code != "Xk3fQa" || signed {
+		t.Errorf("bare code: openShort = %q, %v, %v", code, signed, err)
+	}
+	if code, signed, err := uc.openShort(token); err != nil || code != "Xk3fQa" || !signed {
+		t.Errorf("token: openShort = %q, %v, %v", code, signed, err)
+	}
+	if _, _, err := uc.openShort(replacePart(token, 0, "other1")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("forged token: openShort error = %v, want ErrNotFound", err)
+	}
+
+	unsigned := NewURLShortenerUseCase(nil, nil, nil)
+	if _, _, err := unsigned.openShort(token); !errors.Is(err, ErrNotFound) {
+		t.Errorf("token without signer: openShort error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	signedLink := &domain.URL{Short: "Xk3fQa", Signed: true}
+	plainLink := &domain.URL{Short: "Xk3fQa"}
+
+	tests := []struct {
+		name   string
+		u      *domain.URL
+		signed bool
+		want   bool
+	}{
+		{name: "signed link by token", u: signedLink, signed: true, want: true},
+		{name: "signed link by bare code", u: signedLink, signed: false, want: false},
+		{name: "plain link by code", u: plainLink, signed: false, want: true},
+		{name: "plain link by token", u: plainLink, signed: true, want: false},
+		{name: "missing link", u: nil, signed: false, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reachable(tt.u, tt.signed); got != tt.want {
+				t.Errorf("reachable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
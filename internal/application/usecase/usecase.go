@@ -1,14 +1,47 @@
 package usecase
 
 import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
 	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
 )
 
+// Settings tune use case behaviour, zero values fall back to defaults
+type Settings struct {
+	BaseURL             string
+	DefaultRedirectCode int
+	SelfLinkPolicy      string        // SelfLinkResolve or SelfLinkReject
+	ShortenerPolicy     string        // ShortenerWarn, ShortenerReject or ShortenerExpand
+	HealthFallbackAfter time.Duration // 0 disables redirecting broken links to their fallback
+	DefaultFallbackURL  string        // for expired or disabled links without their own fallback
+	ReuseExisting       bool          // return an existing link for a known destination by default
+	CodeMaxAttempts     int           // generated codes tried before giving up
+	CodeGrowAfter       int           // collisions before asking for longer codes
+	AllowAnonymous      bool          // links may be created without an account or API key
+}
+
 type URLShortenerUseCase struct {
-	repo       ports.URLRepository
-	cache      ports.Cache
-	geoService ports.GeoService
-	validator  *ShortCodeValidator
+	repo          ports.URLRepository
+	cache         ports.Cache
+	geoService    ports.GeoService
+	validator     *ShortCodeValidator
+	policy        *DestinationPolicy
+	resolver      ports.RedirectResolver
+	prober        ports.HealthProber
+	canonicalizer *Canonicalizer
+	generator     ports.CodeGenerator
+	codeFilter    *CodeFilter
+	reservedWords ports.ReservedWordRepository
+	webhooks      ports.WebhookRepository
+	domains       ports.DomainRepository
+	signer        *LinkSigner
+	warningKey    []byte
+	settings      Settings
+	baseURL       *url.URL
 }
 
 func NewURLShortenerUseCase(
@@ -17,9 +50,141 @@ func NewURLShortenerUseCase(
 	geoService ports.GeoService,
 ) *URLShortenerUseCase {
 	return &URLShortenerUseCase{
-		repo:       repo,
-		cache:      cache,
-		geoService: geoService,
-		validator:  NewShortCodeValidator(),
+		repo:          repo,
+		cache:         cache,
+		geoService:    geoService,
+		validator:     NewShortCodeValidator(),
+		policy:        NewDestinationPolicy(PolicyOptions{}, nil, nil, nil),
+		canonicalizer: NewCanonicalizer(CanonicalOptions{}),
+		codeFilter:    NewCodeFilter(nil, CodeFilterOptions{}),
+		warningKey:    randomWarningKey(),
+		settings: Settings{
+			DefaultRedirectCode: http.StatusFound,
+			SelfLinkPolicy:      SelfLinkResolve,
+			ShortenerPolicy:     ShortenerWarn,
+			CodeMaxAttempts:     defaultCodeMaxAttempts,
+			CodeGrowAfter:       defaultCodeGrowAfter,
+			AllowAnonymous:      true,
+		},
+	}
+}
+
+func (uc *URLShortenerUseCase) WithRedirectResolver(r ports.RedirectResolver) *URLShortenerUseCase {
+	uc.resolver = r
+	return uc
+}
+
+// WithWebhooks queues link events for webhooks, without it none are sent
+func (uc *URLShortenerUseCase) WithWebhooks(w ports.WebhookRepository) *URLShortenerUseCase {
+	uc.webhooks = w
+	return uc
+}
+
+// WithDomains enables custom domains, without it every link is on the default domain
+func (uc *URLShortenerUseCase) WithDomains(d ports.DomainRepository) *URLShortenerUseCase {
+	uc.domains = d
+	return uc
+}
+
+// WithLinkSigner enables signed links, without it tokens are never valid
+func (uc *URLShortenerUseCase) WithLinkSigner(s *LinkSigner) *URLShortenerUseCase {
+	uc.signer = s
+	return uc
+}
+
+// WithWarningSecret sets the key of warning page tickets. Without it each
+// process signs with its own random key, so tickets do not survive a restart
+// or reach another instance.
+func (uc *URLShortenerUseCase) WithWarningSecret(secret string) *URLShortenerUseCase {
+	if secret != "" {
+		uc.warningKey = []byte(secret)
+	}
+	return uc
+}
+
+func (uc *URLShortenerUseCase) WithHealthProber(p ports.HealthProber) *URLShortenerUseCase {
+	uc.prober = p
+	return uc
+}
+
+// WithCodeGenerator sets the short code strategy, GenerateShortCode is used without one
+func (uc *URLShortenerUseCase) WithCodeGenerator(g ports.CodeGenerator) *URLShortenerUseCase {
+	uc.generator = g
+	return uc
+}
+
+// WithReservedWords sets the filter for short codes and the store of
+// admin-managed words behind it
+func (uc *URLShortenerUseCase) WithReservedWords(f *CodeFilter, words ports.ReservedWordRepository) *URLShortenerUseCase {
+	if f != nil {
+		uc.codeFilter = f
+	}
+	uc.reservedWords = words
+	return uc
+}
+
+func (uc *URLShortenerUseCase) WithCanonicalizer(c *Canonicalizer) *URLShortenerUseCase {
+	if c != nil {
+		uc.canonicalizer = c
+	}
+	return uc
+}
+
+func (uc *URLShortenerUseCase) WithDestinationPolicy(p *DestinationPolicy) *URLShortenerUseCase {
+	if p != nil {
+		uc.policy = p
+	}
+	return uc
+}
+
+func (uc *URLShortenerUseCase) WithSettings(s Settings) *URLShortenerUseCase {
+	if s.DefaultRedirectCode == 0 {
+		s.DefaultRedirectCode = http.StatusFound
+	} else if !domain.IsValidRedirectCode(s.DefaultRedirectCode) {
+		zlog.Logger.Warn().Int("code", s.DefaultRedirectCode).Msg("invalid default redirect code, using 302")
+		s.DefaultRedirectCode = http.StatusFound
+	}
+
+	switch s.SelfLinkPolicy {
+	case SelfLinkResolve, SelfLinkReject:
+	default:
+		s.SelfLinkPolicy = SelfLinkResolve
+	}
+
+	switch s.ShortenerPolicy {
+	case ShortenerWarn, ShortenerReject, ShortenerExpand:
+	default:
+		s.ShortenerPolicy = ShortenerWarn
+	}
+
+	if s.CodeMaxAttempts <= 0 {
+		s.CodeMaxAttempts = defaultCodeMaxAttempts
+	}
+	if s.CodeGrowAfter <= 0 {
+		s.CodeGrowAfter = defaultCodeGrowAfter
+	}
+
+	if s.HealthFallbackAfter < 0 {
+		s.HealthFallbackAfter = 0
 	}
+
+	if s.DefaultFallbackURL != "" {
+		if _, err := canonicalizeURL(s.DefaultFallbackURL); err != nil {
+			zlog.Logger.Warn().Err(err).Str("fallback_url", s.DefaultFallbackURL).Msg("invalid default fallback URL, ignoring")
+			s.DefaultFallbackURL = ""
+		}
+	}
+
+	uc.baseURL = nil
+	if s.BaseURL != "" {
+		base, err := canonicalizeURL(s.BaseURL)
+		if err != nil {
+			zlog.Logger.Warn().Err(err).Str("base_url", s.BaseURL).Msg("invalid base URL, self links will not be detected")
+		} else {
+			uc.baseURL = base
+		}
+	}
+
+	uc.settings = s
+	return uc
 }
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const maxUTMLength = 255
+
+func buildUTM(spec dto.UTMSpec, mode string) (domain.UTM, string, error) {
+	utm := domain.UTM{
+		Source:   strings.TrimSpace(spec.Source),
+		Medium:   strings.TrimSpace(spec.Medium),
+		Campaign: strings.TrimSpace(spec.Campaign),
+		Term:     strings.TrimSpace(spec.Term),
+		Content:  strings.TrimSpace(spec.Content),
+	}
+
+	for name, value := range utmParams(utm) {
+		if len(value) > maxUTMLength {
+			return domain.UTM{}, "", fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidUTM, name, maxUTMLength)
+		}
+	}
+
+	switch mode {
+	case "":
+		mode = domain.UTMMerge
+	case domain.UTMMerge, domain.UTMOverride:
+	default:
+		return domain.UTM{}, "", fmt.Errorf("%w: unknown mode %q", ErrInvalidUTM, mode)
+	}
+
+	return utm, mode, nil
+}
+
+func utmParams(utm domain.UTM) map[string]string {
+	return map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	}
+}
+
+// applyUTM sets the link's UTM tags on dest. In merge mode tags already present
+// in dest (including ones forwarded from the visitor) are kept.
+func applyUTM(dest string, u *domain.URL) (string, error) {
+	if u.UTM.IsZero() {
+		return dest, nil
+	}
+
+	target, err := url.Parse(dest)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination: %w", err)
+	}
+
+	query := target.Query()
+	tags := url.Values{}
+	replaced := false
+	for name, value := range utmParams(u.UTM) {
+		if value == "" {
+			continue
+		}
+		if query.Has(name) {
+			if u.UTMMode != domain.UTMOverride {
+				continue
+			}
+			query.Del(name)
+			replaced = true
+		}
+		tags.Set(name, value)
+	}
+
+	if replaced {
+		target.RawQuery = query.Encode()
+	}
+	appendQuery(target, tags)
+
+	return target.String(), nil
+}
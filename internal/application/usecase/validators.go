@@ -4,10 +4,10 @@ import (
 	"crypto/md5"
 	"encoding/base64"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/yokitheyo/URLShortener/internal/domain"
 )
 
 func GenerateShortCode() (string, error) {
@@ -21,25 +21,14 @@ func GenerateShortCode() (string, error) {
 	return strings.TrimRight(encoded, "=")[:6], nil
 }
 
-type ShortCodeValidator struct {
-	customCodeRegex *regexp.Regexp
-}
+// ShortCodeValidator checks custom codes against the limits in domain
+type ShortCodeValidator struct{}
 
 func NewShortCodeValidator() *ShortCodeValidator {
-	return &ShortCodeValidator{
-		customCodeRegex: regexp.MustCompile(`^[a-zA-Z0-9_-]+$`),
-	}
+	return &ShortCodeValidator{}
 }
 
+// ValidateCustomShort expects a code normalized with domain.NormalizeShortCode
 func (v *ShortCodeValidator) ValidateCustomShort(code string) error {
-	if len(code) < 3 {
-		return fmt.Errorf("short code must be at least 3 characters")
-	}
-	if len(code) > 50 {
-		return fmt.Errorf("short code must be at most 50 characters")
-	}
-	if !v.customCodeRegex.MatchString(code) {
-		return fmt.Errorf("short code can only contain alphanumeric characters, underscore, and hyphen")
-	}
-	return nil
+	return domain.ValidateShortCode(code)
 }
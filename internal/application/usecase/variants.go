@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	maxVariants      = 10
+	maxVariantWeight = 10000
+)
+
+var variantLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// buildVariants validates requested variants and converts them to domain objects.
+// Missing labels are filled in as A, B, C...
+func buildVariants(specs []dto.VariantSpec) ([]domain.Variant, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	if len(specs) < 2 || len(specs) > maxVariants {
+		return nil, fmt.Errorf("%w: between 2 and %d variants are required", ErrInvalidVariants, maxVariants)
+	}
+
+	seen := make(map[string]struct{}, len(specs))
+	variants := make([]domain.Variant, 0, len(specs))
+	for i, spec := range specs {
+		label := spec.Label
+		if label == "" {
+			label = string(rune('A' + i))
+		}
+		if !variantLabelRegex.MatchString(label) {
+			return nil, fmt.Errorf("%w: invalid label %q", ErrInvalidVariants, label)
+		}
+		if _, ok := seen[label]; ok {
+			return nil, fmt.Errorf("%w: duplicate label %q", ErrInvalidVariants, label)
+		}
+		seen[label] = struct{}{}
+
+		dest := strings.TrimSpace(spec.URL)
+		if dest == "" {
+			return nil, fmt.Errorf("%w: variant %q has no URL", ErrInvalidVariants, label)
+		}
+		if spec.Weight <= 0 || spec.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("%w: variant %q weight must be between 1 and %d", ErrInvalidVariants, label, maxVariantWeight)
+		}
+
+		variants = append(variants, domain.Variant{
+			Label:       label,
+			Destination: dest,
+			Weight:      spec.Weight,
+		})
+	}
+
+	return variants, nil
+}
+
+func normalizeStickyMode(mode string) (string, error) {
+	switch mode {
+	case "", domain.StickyCookie:
+		return domain.StickyCookie, nil
+	case domain.StickyIP:
+		return domain.StickyIP, nil
+	default:
+		return "", fmt.Errorf("%w: unknown sticky mode %q", ErrInvalidVariants, mode)
+	}
+}
+
+// selectVariant picks the variant for a visitor. A remembered cookie wins,
+// IP-sticky links hash the client address, everything else is a weighted roll.
+func selectVariant(u *domain.URL, cmd dto.RedirectCommand) *domain.Variant {
+	if len(u.Variants) == 0 {
+		return nil
+	}
+
+	if u.StickyMode == domain.StickyCookie && cmd.VariantCookie != "" {
+		for i := range u.Variants {
+			if u.Variants[i].Label == cmd.VariantCookie {
+				return &u.Variants[i]
+			}
+		}
+	}
+
+	var roll uint64
+	if u.StickyMode == domain.StickyIP && cmd.Meta.IP != "" {
+		h := fnv.New64a()
+		h.Write([]byte(u.Short + "|" + cmd.Meta.IP))
+		roll = h.Sum64()
+	} else {
+		roll = rand.Uint64()
+	}
+
+	return pickWeighted(u.Variants, roll)
+}
+
+func pickWeighted(variants []domain.Variant, roll uint64) *domain.Variant {
+	var total uint64
+	for _, v := range variants {
+		total += uint64(v.Weight)
+	}
+	if total == 0 {
+		return &variants[0]
+	}
+
+	n := roll % total
+	for i := range variants {
+		w := uint64(variants[i].Weight)
+		if n < w {
+			return &variants[i]
+		}
+		n -= w
+	}
+	return &variants[len(variants)-1]
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+func TestPickWeightedDistribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []domain.Variant
+		want     map[string]int // picks per label over one roll per unit of weight
+	}{
+		{
+			name:     "even",
+			variants: []domain.Variant{{Label: "A", Weight: 1}, {Label: "B", Weight: 1}},
+			want:     map[string]int{"A": 1, "B": 1},
+		},
+		{
+			name:     "weighted",
+			variants: []domain.Variant{{Label: "A", Weight: 70}, {Label: "B", Weight: 20}, {Label: "C", Weight: 10}},
+			want:     map[string]int{"A": 70, "B": 20, "C": 10},
+		},
+		{
+			name:     "zero weight never picked",
+			variants: []domain.Variant{{Label: "A", Weight: 0}, {Label: "B", Weight: 3}, {Label: "C", Weight: 0}},
+			want:     map[string]int{"B": 3},
+		},
+		{
+			name:     "all zero falls back to the first",
+			variants: []domain.Variant{{Label: "A", Weight: 0}, {Label: "B", Weight: 0}},
+			want:     map[string]int{"A": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rolls := 0
+			for _, n := range tt.want {
+				rolls += n
+			}
+			// consecutive rolls cover every remainder exactly once,
+			// so the picks match the weights exactly
+			got := make(map[string]int)
+			for roll := uint64(1 << 40); roll < 1<<40+uint64(rolls); roll++ {
+				got[pickWeighted(tt.variants, roll).Label]++
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("picks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectVariant(t *testing.T) {
+	variants := []domain.Variant{{Label: "A", Weight: 1}, {Label: "B", Weight: 0}}
+
+	tests := []struct {
+		name   string
+		sticky string
+		cookie string
+		want   string
+	}{
+		{name: "cookie remembered", sticky: domain.StickyCookie, cookie: "B", want: "B"},
+		{name: "unknown cookie rolls", sticky: domain.StickyCookie, cookie: "Z", want: "A"},
+		{name: "cookie ignored when sticky by IP", sticky: domain.StickyIP, cookie: "B", want: "A"},
+		{name: "no cookie rolls", sticky: domain.StickyCookie, want: "A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &domain.URL{Short: "Xk3fQa", StickyMode: tt.sticky, Variants: variants}
+			cmd := dto.RedirectCommand{VariantCookie: tt.cookie, Meta: dto.ClickMetadata{IP: "198.51.100.7"}}
+			if got := selectVariant(u, cmd); got == nil || got.Label != tt.want {
+				t.Errorf("selectVariant = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := selectVariant(&domain.URL{}, dto.RedirectCommand{}); got != nil {
+		t.Errorf("link without variants: selectVariant = %+v, want nil", got)
+	}
+}
+
+func TestSelectVariantStickyIP(t *testing.T) {
+	variants := []domain.Variant{{Label: "A", Weight: 50}, {Label: "B", Weight: 50}}
+	u := &domain.URL{Short: "Xk3fQa", StickyMode: domain.StickyIP, Variants: variants}
+
+	seen := make(map[string]bool)
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3", "198.51.100.4", "198.51.100.5", "198.51.100.6", "198.51.100.7", "198.51.100.8"} {
+		cmd := dto.RedirectCommand{Meta: dto.ClickMetadata{IP: ip}}
+		first := selectVariant(u, cmd).Label
+		for i := 0; i < 5; i++ {
+			if got := selectVariant(u, cmd).Label; got != first {
+				t.Fatalf("%s got %s after %s", ip, got, first)
+			}
+		}
+		seen[first] = true
+	}
+	if len(seen) != len(variants) {
+		t.Errorf("eight addresses all landed on %v", seen)
+	}
+}
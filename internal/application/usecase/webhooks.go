@@ -0,0 +1,321 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	wbfretry "github.com/wb-go/wbf/retry"
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	internalRetry "github.com/yokitheyo/URLShortener/internal/retry"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 24
+
+	defaultDeliveryLease = time.Minute
+	maxDeliveryDelay     = 24 * time.Hour
+	maxDeliveryErrorLen  = 500
+
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// WebhookOptions tune deliveries, zero values fall back to defaults
+type WebhookOptions struct {
+	// Retry spaces the attempts of a delivery the way wbf/retry does: Delay
+	// after the first failure, multiplied by Backoff after each further one,
+	// dead-lettered after Attempts failures. The wait is stored with the
+	// delivery instead of slept, so retries survive restarts and any
+	// dispatcher may pick them up.
+	Retry wbfretry.Strategy
+	Lease time.Duration // a claimed delivery is retried after this if its dispatcher died
+}
+
+// WebhookService manages webhook subscriptions and delivers the events queued
+// for them in the outbox
+type WebhookService struct {
+	hooks   ports.WebhookRepository
+	urls    ports.URLRepository
+	domains ports.DomainRepository
+	policy  *DestinationPolicy
+	sender  ports.WebhookSender
+	opts    WebhookOptions
+}
+
+func NewWebhookService(hooks ports.WebhookRepository, urls ports.URLRepository, domains ports.DomainRepository, policy *DestinationPolicy, sender ports.WebhookSender, opts WebhookOptions) *WebhookService {
+	if opts.Retry.Attempts <= 0 {
+		opts.Retry = internalRetry.DefaultStrategy
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaultDeliveryLease
+	}
+	return &WebhookService{hooks: hooks, urls: urls, domains: domains, policy: policy, sender: sender, opts: opts}
+}
+
+// Create subscribes a URL to events of the actor's workspace, or of one of
+// its links. Owners and editors may. The secret is only returned here.
+func (s *WebhookService) Create(ctx context.Context, cmd dto.CreateWebhookCommand) (*domain.Webhook, error) {
+	if err := requireWriter(cmd.Actor); err != nil {
+		return nil, err
+	}
+	if cmd.Actor.WorkspaceID == 0 {
+		return nil, fmt.Errorf("%w: pick the workspace with the X-Workspace-ID header", ErrInvalidWebhook)
+	}
+
+	target, err := s.policy.Check(ctx, cmd.URL)
+	if err != nil {
+		return nil, fmt.Errorf("webhook url: %w", err)
+	}
+
+	events := cmd.Events
+	if len(events) == 0 {
+		events = domain.WebhookEvents
+	}
+	for _, e := range events {
+		if !slices.Contains(domain.WebhookEvents, e) {
+			return nil, fmt.Errorf("%w: events must be among %s", ErrInvalidWebhook, strings.Join(domain.WebhookEvents, ", "))
+		}
+	}
+
+	hook := &domain.Webhook{
+		WorkspaceID: cmd.Actor.WorkspaceID,
+		URL:         target.String(),
+		Events:      slices.Compact(slices.Sorted(slices.Values(events))),
+		CreatedBy:   cmd.Actor.UserID,
+		CreatedAt:   time.Now(),
+	}
+
+	if cmd.Short != "" {
+		domainID, err := linkDomainID(ctx, s.domains, cmd.Actor.WorkspaceID, cmd.Domain)
+		if err != nil {
+			return nil, err
+		}
+		u, err := s.urls.FindByShort(ctx, cmd.Actor.WorkspaceID, domainID, domain.NormalizeShortCode(cmd.Short))
+		if err != nil {
+			zlog.Logger.Error().Err(err).Msg("repo error finding URL")
+			return nil, fmt.Errorf("database error")
+		}
+		if u == nil {
+			return nil, ErrNotFound
+		}
+		hook.LinkID = u.ID
+		hook.Short = u.Short
+	}
+
+	hook.Secret, err = newWebhookSecret()
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to generate webhook secret")
+		return nil, fmt.Errorf("failed to create webhook")
+	}
+	if err := s.hooks.Create(ctx, hook); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to create webhook")
+		return nil, fmt.Errorf("failed to create webhook")
+	}
+	return hook, nil
+}
+
+// List returns the webhooks of the actor's workspace
+func (s *WebhookService) List(ctx context.Context, actor domain.Actor) ([]*domain.Webhook, error) {
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := s.hooks.List(ctx, scope)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list webhooks")
+		return nil, fmt.Errorf("failed to list webhooks")
+	}
+	return hooks, nil
+}
+
+// Delete removes a webhook with its deliveries, for the same callers as Create
+func (s *WebhookService) Delete(ctx context.Context, actor domain.Actor, id int64) error {
+	if err := requireWriter(actor); err != nil {
+		return err
+	}
+	scope, err := workspaceScope(actor)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.hooks.Delete(ctx, scope, id)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to delete webhook")
+		return fmt.Errorf("failed to delete webhook")
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Deliveries returns the delivery log of a webhook, newest first
+func (s *WebhookService) Deliveries(ctx context.Context, query dto.DeliveriesQuery) ([]*domain.WebhookDelivery, error) {
+	scope, err := workspaceScope(query.Actor)
+	if err != nil {
+		return nil, err
+	}
+	if query.Status != "" && !slices.Contains(domain.DeliveryStatuses, query.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalidQuery, strings.Join(domain.DeliveryStatuses, ", "))
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultDeliveriesLimit
+	}
+	if query.Limit > maxDeliveriesLimit {
+		query.Limit = maxDeliveriesLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	hook, err := s.hooks.FindByID(ctx, scope, query.WebhookID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up webhook")
+		return nil, fmt.Errorf("database error")
+	}
+	if hook == nil {
+		return nil, ErrNotFound
+	}
+
+	deliveries, err := s.hooks.ListDeliveries(ctx, hook.ID, query.Status, query.Limit, query.Offset)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list webhook deliveries")
+		return nil, fmt.Errorf("failed to list deliveries")
+	}
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries takes up to limit due deliveries off the outbox
+func (s *WebhookService) ClaimDueDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error) {
+	return s.hooks.ClaimDue(ctx, limit, s.opts.Lease)
+}
+
+// Deliver sends one claimed delivery and records the outcome. Failed
+// deliveries are retried with backoff until they run out of attempts.
+func (s *WebhookService) Deliver(ctx context.Context, d *domain.WebhookDelivery) error {
+	hook, err := s.hooks.FindByID(ctx, domain.AllWorkspaces, d.WebhookID)
+	if err != nil {
+		return err
+	}
+	if hook == nil {
+		return nil // deleted since, its deliveries went with it
+	}
+
+	status, sendErr := s.sender.Send(ctx, hook.URL, hook.Secret, d)
+	if sendErr == nil {
+		return s.hooks.MarkDelivered(ctx, d.ID, status)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err() // shutting down, the lease brings it back
+	}
+
+	attempts := d.Attempts + 1
+	dead := attempts >= s.opts.Retry.Attempts
+	reason := sendErr.Error()
+	if len(reason) > maxDeliveryErrorLen {
+		reason = reason[:maxDeliveryErrorLen]
+	}
+	if err := s.hooks.MarkFailed(ctx, d.ID, status, reason, time.Now().Add(s.retryDelay(attempts)), dead); err != nil {
+		return err
+	}
+	if dead {
+		zlog.Logger.Warn().Err(sendErr).Int64("delivery_id", d.ID).Int64("webhook_id", hook.ID).
+			Int("attempts", attempts).Msg("webhook delivery dead-lettered")
+	}
+	return nil
+}
+
+// retryDelay is the wait after the attempts-th failure, the delay wbf/retry
+// would sleep before the next attempt
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.opts.Retry.Delay
+	for i := 1; i < attempts && delay < maxDeliveryDelay; i++ {
+		delay = time.Duration(float64(delay) * s.opts.Retry.Backoff)
+	}
+	return min(delay, maxDeliveryDelay)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// webhookPayload is the JSON body of every delivery
+type webhookPayload struct {
+	Event       string        `json:"event"`
+	OccurredAt  int64         `json:"occurred_at"`
+	WorkspaceID int64         `json:"workspace_id"`
+	Link        webhookLink   `json:"link"`
+	Click       *webhookClick `json:"click,omitempty"`
+}
+
+type webhookLink struct {
+	Domain    string `json:"domain"` // host the link is served on, codes are unique per domain
+	Short     string `json:"short"`
+	Original  string `json:"original"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	Disabled  bool   `json:"disabled"`
+}
+
+type webhookClick struct {
+	Location string `json:"location,omitempty"`
+	Referrer string `json:"referrer,omitempty"`
+	Device   string `json:"device,omitempty"`
+	Variant  string `json:"variant,omitempty"`
+}
+
+// enqueueWebhooks queues event on u, served on host, for the webhooks
+// subscribed to it. Links outside workspaces have no webhooks. Failures are
+// logged, they never fail the request that caused the event.
+func enqueueWebhooks(ctx context.Context, hooks ports.WebhookRepository, event string, u *domain.URL, host string, click *domain.Click) {
+	if hooks == nil || u.WorkspaceID == 0 {
+		return
+	}
+
+	payload := webhookPayload{
+		Event:       event,
+		OccurredAt:  time.Now().Unix(),
+		WorkspaceID: u.WorkspaceID,
+		Link: webhookLink{
+			Domain:    host,
+			Short:     u.Short,
+			Original:  u.Original,
+			CreatedAt: u.CreatedAt.Unix(),
+			ExpiresAt: u.ExpiresAt.Unix(),
+			Disabled:  u.IsDisabled,
+		},
+	}
+	if click != nil {
+		payload.OccurredAt = click.OccurredAt.Unix()
+		payload.Click = &webhookClick{
+			Location: click.IP, // clicks keep the location, not the address
+			Referrer: click.Referrer,
+			Device:   click.Device,
+			Variant:  click.Variant,
+		}
+	}
+
+	doc, err := json.Marshal(payload)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to encode webhook payload")
+		return
+	}
+	if _, err := hooks.Enqueue(ctx, u.WorkspaceID, u.ID, event, doc); err != nil {
+		zlog.Logger.Warn().Err(err).Str("short", u.Short).Str("event", event).Msg("failed to queue webhooks")
+	}
+}
@@ -0,0 +1,301 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	wbfretry "github.com/wb-go/wbf/retry"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/webhook"
+	internalRetry "github.com/yokitheyo/URLShortener/internal/retry"
+)
+
+// deliveryRepo keeps one webhook and the outcome of its deliveries
+type deliveryRepo struct {
+	ports.WebhookRepository
+	hook *domain.Webhook
+
+	mu        sync.Mutex
+	delivered []int
+	failures  []deliveryFailure
+}
+
+type deliveryFailure struct {
+	status        int
+	nextAttemptAt time.Time
+	dead          bool
+}
+
+func (r *deliveryRepo) FindByID(_ context.Context, _, id int64) (*domain.Webhook, error) {
+	if r.hook == nil || r.hook.ID != id {
+		return nil, nil
+	}
+	return r.hook, nil
+}
+
+func (r *deliveryRepo) MarkDelivered(_ context.Context, _ int64, status int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivered = append(r.delivered, status)
+	return nil
+}
+
+func (r *deliveryRepo) MarkFailed(_ context.Context, _ int64, status int, _ string, nextAttemptAt time.Time, dead bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, deliveryFailure{status: status, nextAttemptAt: nextAttemptAt, dead: dead})
+	return nil
+}
+
+// receiver answers deliveries with the statuses in turn, the last one from then on
+type receiver struct {
+	srv      *httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	hits     int
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	rc := &receiver{statuses: statuses}
+	rc.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc.mu.Lock()
+		status := rc.statuses[min(rc.hits, len(rc.statuses)-1)]
+		rc.hits++
+		rc.mu.Unlock()
+
+		timestamp := r.Header.Get(webhook.HeaderTimestamp)
+		if r.Header.Get(webhook.HeaderSignature) == "" || timestamp == "" {
+			t.Error("delivery is not signed")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rc.srv.Close)
+	return rc
+}
+
+func newDeliveryService(rc *receiver, opts WebhookOptions) (*WebhookService, *deliveryRepo) {
+	repo := &deliveryRepo{hook: &domain.Webhook{ID: 7, URL: rc.srv.URL, Secret: "whsec_test"}}
+	sender := webhook.NewHTTPSender(rc.srv.Client(), time.Second)
+	return NewWebhookService(repo, nil, nil, nil, sender, opts), repo
+}
+
+// deliver runs d the way the dispatcher does until it is delivered or dead,
+// at most limit times
+func deliver(t *testing.T, s *WebhookService, repo *deliveryRepo, d *domain.WebhookDelivery, limit int) {
+	t.Helper()
+	for i := 0; i < limit; i++ {
+		if err := s.Deliver(context.Background(), d); err != nil {
+			t.Fatalf("Deliver: %v", err)
+		}
+		if len(repo.delivered) > 0 || (len(repo.failures) > 0 && repo.failures[len(repo.failures)-1].dead) {
+			return
+		}
+		d.Attempts++
+	}
+}
+
+func TestDeliverSchedulesRetriesWithBackoff(t *testing.T) {
+	rc := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+	s, repo := newDeliveryService(rc, WebhookOptions{Retry: wbfretry.Strategy{Attempts: 5, Delay: time.Minute, Backoff: 3}})
+
+	start := time.Now()
+	deliver(t, s, repo, &domain.WebhookDelivery{ID: 1, WebhookID: 7, Event: domain.EventLinkClicked, Payload: []byte(`{}`)}, 10)
+	end := time.Now()
+
+	wantDelays := []time.Duration{time.Minute, 3 * time.Minute, 9 * time.Minute}
+	wantStatuses := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+	if len(repo.failures) != len(wantDelays) {
+		t.Fatalf("%d failures recorded, want %d", len(repo.failures), len(wantDelays))
+	}
+	for i, f := range repo.failures {
+		if f.dead {
+			t.Errorf("attempt %d dead-lettered early", i+1)
+		}
+		if f.status != wantStatuses[i] {
+			t.Errorf("attempt %d status = %d, want %d", i+1, f.status, wantStatuses[i])
+		}
+		if f.nextAttemptAt.Before(start.Add(wantDelays[i])) || f.nextAttemptAt.After(end.Add(wantDelays[i])) {
+			t.Errorf("attempt %d retried in %v, want %v", i+1, f.nextAttemptAt.Sub(start), wantDelays[i])
+		}
+	}
+	if len(repo.delivered) != 1 || repo.delivered[0] != http.StatusOK {
+		t.Errorf("delivered = %v, want one delivery answered 200", repo.delivered)
+	}
+	if rc.hits != 4 {
+		t.Errorf("receiver got %d requests, want 4", rc.hits)
+	}
+}
+
+func TestDeliverGivesUpAfterMaxAttempts(t *testing.T) {
+	rc := newReceiver(t, http.StatusInternalServerError)
+	s, repo := newDeliveryService(rc, WebhookOptions{Retry: wbfretry.Strategy{Attempts: 3, Delay: time.Second, Backoff: 2}})
+
+	deliver(t, s, repo, &domain.WebhookDelivery{ID: 1, WebhookID: 7, Event: domain.EventLinkClicked, Payload: []byte(`{}`)}, 10)
+
+	if len(repo.failures) != 3 {
+		t.Fatalf("%d failures recorded, want 3", len(repo.failures))
+	}
+	for i, f := range repo.failures {
+		if want := i == 2; f.dead != want {
+			t.Errorf("attempt %d dead = %v, want %v", i+1, f.dead, want)
+		}
+	}
+	if len(repo.delivered) != 0 || rc.hits != 3 {
+		t.Errorf("delivered %v after %d requests, want nothing after 3", repo.delivered, rc.hits)
+	}
+}
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	s := NewWebhookService(nil, nil, nil, nil, nil, WebhookOptions{Retry: wbfretry.Strategy{Attempts: 8, Delay: time.Hour, Backoff: 10}})
+
+	if got := s.retryDelay(1); got != time.Hour {
+		t.Errorf("retryDelay(1) = %v, want 1h", got)
+	}
+	if got := s.retryDelay(8); got != maxDeliveryDelay {
+		t.Errorf("retryDelay(8) = %v, want %v", got, maxDeliveryDelay)
+	}
+}
+
+func TestDeliverSkipsDeletedWebhook(t *testing.T) {
+	rc := newReceiver(t, http.StatusOK)
+	s, repo := newDeliveryService(rc, WebhookOptions{})
+	repo.hook = nil
+
+	if err := s.Deliver(context.Background(), &domain.WebhookDelivery{ID: 1, WebhookID: 7}); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if rc.hits != 0 || len(repo.failures) != 0 || len(repo.delivered) != 0 {
+		t.Error("delivery to a deleted webhook was sent or recorded")
+	}
+}
+
+func TestWebhookRetryDefaultsToDefaultStrategy(t *testing.T) {
+	s := NewWebhookService(nil, nil, nil, nil, nil, WebhookOptions{})
+
+	if s.opts.Retry != internalRetry.DefaultStrategy {
+		t.Errorf("Retry = %+v, want internal/retry.DefaultStrategy", s.opts.Retry)
+	}
+	if got, want := s.retryDelay(2), time.Duration(float64(internalRetry.DefaultStrategy.Delay)*internalRetry.DefaultStrategy.Backoff); got != want {
+		t.Errorf("retryDelay(2) = %v, want %v", got, want)
+	}
+}
+
+// outboxRepo keeps the payloads queued for webhooks
+type outboxRepo struct {
+	ports.WebhookRepository
+	payloads []webhookPayload
+}
+
+func (r *outboxRepo) Enqueue(_ context.Context, _, _ int64, _ string, payload json.RawMessage) (int, error) {
+	var p webhookPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return 0, err
+	}
+	r.payloads = append(r.payloads, p)
+	return 1, nil
+}
+
+// clickRepo serves links with the same code on two domains
+type clickRepo struct {
+	ports.URLRepository
+	links []*domain.URL
+}
+
+func (r *clickRepo) FindByShort(_ context.Context, _, domainID int64, short string) (*domain.URL, error) {
+	for _, u := range r.links {
+		if u.DomainID == domainID && u.Short == short {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *clickRepo) ClaimExpired(context.Context, int) ([]*domain.URL, error) { return r.links, nil }
+
+func (r *clickRepo) GetVariants(context.Context, int64) ([]domain.Variant, error) { return nil, nil }
+func (r *clickRepo) IncrementVisits(context.Context, int64) error                 { return nil }
+func (r *clickRepo) SaveClick(context.Context, *domain.Click) error               { return nil }
+
+// brandRepo holds one verified custom domain
+type brandRepo struct {
+	ports.DomainRepository
+	d *domain.CustomDomain
+}
+
+func (r *brandRepo) FindByHost(_ context.Context, _ int64, host string) (*domain.CustomDomain, error) {
+	if host != r.d.Host {
+		return nil, nil
+	}
+	return r.d, nil
+}
+
+func (r *brandRepo) FindByID(_ context.Context, _, id int64) (*domain.CustomDomain, error) {
+	if id != r.d.ID {
+		return nil, nil
+	}
+	return r.d, nil
+}
+
+func TestWebhookPayloadNamesLinkDomain(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	repo := &clickRepo{links: []*domain.URL{
+		{ID: 1, WorkspaceID: 3, Short: "promo", Original: "https://example.com/a", ExpiresAt: expires},
+		{ID: 2, WorkspaceID: 3, DomainID: 9, Short: "promo", Original: "https://example.com/b", ExpiresAt: expires},
+	}}
+	brand := &domain.CustomDomain{ID: 9, WorkspaceID: 3, Host: "go.brand.example", VerifiedAt: time.Now()}
+
+	newUseCase := func(baseURL string) (*URLShortenerUseCase, *outboxRepo) {
+		outbox := &outboxRepo{}
+		uc := NewURLShortenerUseCase(repo, nil, nil).
+			WithWebhooks(outbox).
+			WithDomains(&brandRepo{d: brand}).
+			WithSettings(Settings{BaseURL: baseURL})
+		return uc, outbox
+	}
+
+	t.Run("clicks", func(t *testing.T) {
+		uc, outbox := newUseCase("https://sho.example/go")
+		for _, host := range []string{"sho.example", "GO.brand.example:443"} {
+			if _, err := uc.Redirect(context.Background(), dto.RedirectCommand{Host: host, Short: "promo"}); err != nil {
+				t.Fatalf("Redirect on %s: %v", host, err)
+			}
+		}
+		assertLinkDomains(t, outbox, "sho.example", "go.brand.example")
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		uc, outbox := newUseCase("https://sho.example/go")
+		if _, err := uc.AnnounceExpired(context.Background(), 10); err != nil {
+			t.Fatalf("AnnounceExpired: %v", err)
+		}
+		assertLinkDomains(t, outbox, "sho.example", "go.brand.example")
+	})
+
+	t.Run("no base URL", func(t *testing.T) {
+		uc, outbox := newUseCase("")
+		if _, err := uc.AnnounceExpired(context.Background(), 10); err != nil {
+			t.Fatalf("AnnounceExpired: %v", err)
+		}
+		assertLinkDomains(t, outbox, "", "go.brand.example")
+	})
+}
+
+func assertLinkDomains(t *testing.T, outbox *outboxRepo, want ...string) {
+	t.Helper()
+	if len(outbox.payloads) != len(want) {
+		t.Fatalf("%d payloads queued, want %d", len(outbox.payloads), len(want))
+	}
+	for i, p := range outbox.payloads {
+		if p.Link.Domain != want[i] {
+			t.Errorf("payload %d of %s: domain = %q, want %q", i+1, p.Link.Short, p.Link.Domain, want[i])
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const workspaceNameLimit = 100
+
+// WorkspaceService manages workspaces and the roles of their members, and
+// decides which workspace a request works in
+type WorkspaceService struct {
+	workspaces ports.WorkspaceRepository
+	users      ports.UserRepository
+}
+
+func NewWorkspaceService(workspaces ports.WorkspaceRepository, users ports.UserRepository) *WorkspaceService {
+	return &WorkspaceService{workspaces: workspaces, users: users}
+}
+
+// Resolve returns actor working in workspaceID with the role it has there.
+// Without workspaceID users work in their first workspace and admins in none,
+// which lets them see every workspace. API keys bound to a workspace cannot
+// pick another one. Admins may enter workspaces they are not a member of.
+func (s *WorkspaceService) Resolve(ctx context.Context, actor domain.Actor, workspaceID int64) (domain.Actor, error) {
+	if actor.WorkspaceID != 0 {
+		if workspaceID != 0 && workspaceID != actor.WorkspaceID {
+			return domain.Actor{}, fmt.Errorf("%w: the API key is bound to workspace %d", ErrForbidden, actor.WorkspaceID)
+		}
+		workspaceID = actor.WorkspaceID
+	}
+
+	if workspaceID == 0 {
+		if actor.UserID == 0 || actor.Admin {
+			return actor, nil
+		}
+		m, err := s.workspaces.FirstMembership(ctx, actor.UserID)
+		if err != nil {
+			zlog.Logger.Error().Err(err).Int64("user_id", actor.UserID).Msg("failed to look up workspace")
+			return domain.Actor{}, fmt.Errorf("database error")
+		}
+		if m == nil {
+			return actor, nil
+		}
+		return actor.InWorkspace(m.WorkspaceID, m.Role), nil
+	}
+
+	role, err := s.roleOf(ctx, actor, workspaceID)
+	if err != nil {
+		return domain.Actor{}, err
+	}
+	return actor.InWorkspace(workspaceID, role), nil
+}
+
+// Create makes a workspace with the calling user as its owner
+func (s *WorkspaceService) Create(ctx context.Context, actor domain.Actor, name string) (*domain.Workspace, error) {
+	if actor.UserID == 0 {
+		return nil, denied(actor)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > workspaceNameLimit {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidWorkspace, workspaceNameLimit)
+	}
+
+	ws := &domain.Workspace{Name: name, CreatedAt: time.Now()}
+	if err := s.workspaces.Create(ctx, ws, actor.UserID); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to create workspace")
+		return nil, fmt.Errorf("failed to create workspace")
+	}
+	return ws, nil
+}
+
+// List returns the workspaces the calling user belongs to
+func (s *WorkspaceService) List(ctx context.Context, actor domain.Actor) ([]domain.Membership, error) {
+	if actor.UserID == 0 {
+		return nil, denied(actor)
+	}
+
+	memberships, err := s.workspaces.ListForUser(ctx, actor.UserID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list workspaces")
+		return nil, fmt.Errorf("failed to list workspaces")
+	}
+	return memberships, nil
+}
+
+// Members lists the members of a workspace to any of its members
+func (s *WorkspaceService) Members(ctx context.Context, actor domain.Actor, workspaceID int64) ([]domain.Member, error) {
+	if _, err := s.roleOf(ctx, actor, workspaceID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaces.ListMembers(ctx, workspaceID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list workspace members")
+		return nil, fmt.Errorf("failed to list members")
+	}
+	return members, nil
+}
+
+// SetMember adds an existing account to a workspace or changes its role.
+// Only owners and admins may, and the last owner cannot be demoted.
+func (s *WorkspaceService) SetMember(ctx context.Context, cmd dto.SetMemberCommand) (*domain.Member, error) {
+	if err := s.requireOwner(ctx, cmd.Actor, cmd.WorkspaceID); err != nil {
+		return nil, err
+	}
+	if !domain.IsValidRole(cmd.Role) {
+		return nil, fmt.Errorf("%w: role must be one of %s", ErrInvalidWorkspace, strings.Join(domain.WorkspaceRoles, ", "))
+	}
+	email, err := normalizeEmail(cmd.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up user")
+		return nil, fmt.Errorf("database error")
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: no account with email %s", ErrNotFound, email)
+	}
+
+	if cmd.Role != domain.RoleOwner {
+		if err := s.keepOwner(ctx, cmd.WorkspaceID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.workspaces.SetMember(ctx, cmd.WorkspaceID, user.ID, cmd.Role); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to save workspace member")
+		return nil, fmt.Errorf("failed to save member")
+	}
+
+	return &domain.Member{
+		WorkspaceID: cmd.WorkspaceID,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        cmd.Role,
+	}, nil
+}
+
+// RemoveMember takes userID out of a workspace. Owners and admins may remove
+// anyone, other members only themselves. The last owner cannot leave.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, actor domain.Actor, workspaceID, userID int64) error {
+	if actor.UserID == 0 || actor.UserID != userID {
+		if err := s.requireOwner(ctx, actor, workspaceID); err != nil {
+			return err
+		}
+	} else if _, err := s.roleOf(ctx, actor, workspaceID); err != nil {
+		return err
+	}
+
+	if err := s.keepOwner(ctx, workspaceID, userID); err != nil {
+		return err
+	}
+
+	removed, err := s.workspaces.RemoveMember(ctx, workspaceID, userID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to remove workspace member")
+		return fmt.Errorf("failed to remove member")
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// roleOf returns the actor's role in workspaceID. Admins who are not members
+// get an empty role, everybody else who is not a member gets ErrForbidden.
+func (s *WorkspaceService) roleOf(ctx context.Context, actor domain.Actor, workspaceID int64) (string, error) {
+	if workspaceID <= 0 {
+		return "", fmt.Errorf("%w: invalid workspace id", ErrInvalidWorkspace)
+	}
+
+	if actor.UserID != 0 {
+		m, err := s.workspaces.FindMember(ctx, workspaceID, actor.UserID)
+		if err != nil {
+			zlog.Logger.Error().Err(err).Msg("failed to look up workspace member")
+			return "", fmt.Errorf("database error")
+		}
+		if m != nil {
+			return m.Role, nil
+		}
+	}
+	if !actor.Admin {
+		return "", fmt.Errorf("%w: not a member of workspace %d", denied(actor), workspaceID)
+	}
+
+	ws, err := s.workspaces.FindByID(ctx, workspaceID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up workspace")
+		return "", fmt.Errorf("database error")
+	}
+	if ws == nil {
+		return "", ErrNotFound
+	}
+	return "", nil
+}
+
+func (s *WorkspaceService) requireOwner(ctx context.Context, actor domain.Actor, workspaceID int64) error {
+	role, err := s.roleOf(ctx, actor, workspaceID)
+	if err != nil {
+		return err
+	}
+	if role != domain.RoleOwner && !actor.Admin {
+		return fmt.Errorf("%w: only owners manage members", ErrForbidden)
+	}
+	return nil
+}
+
+// keepOwner fails if userID is the only owner of workspaceID
+func (s *WorkspaceService) keepOwner(ctx context.Context, workspaceID, userID int64) error {
+	m, err := s.workspaces.FindMember(ctx, workspaceID, userID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to look up workspace member")
+		return fmt.Errorf("database error")
+	}
+	if m == nil || m.Role != domain.RoleOwner {
+		return nil
+	}
+
+	owners, err := s.workspaces.CountOwners(ctx, workspaceID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to count workspace owners")
+		return fmt.Errorf("database error")
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
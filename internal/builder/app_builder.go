@@ -1,7 +1,10 @@
 package builder
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"time"
 
@@ -17,11 +20,23 @@ import (
 	"github.com/yokitheyo/URLShortener/internal/application/usecase"
 	"github.com/yokitheyo/URLShortener/internal/config"
 	"github.com/yokitheyo/URLShortener/internal/geoip"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/codegen"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/domaincheck"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/eventsink"
 	"github.com/yokitheyo/URLShortener/internal/infrastructure/geolocation"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/healthcheck"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/netguard"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/ratelimit"
 	"github.com/yokitheyo/URLShortener/internal/infrastructure/repository"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/resolver"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/safety"
 	"github.com/yokitheyo/URLShortener/internal/infrastructure/storage"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/webhook"
 	"github.com/yokitheyo/URLShortener/internal/presentation/handlers"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
 	internalRetry "github.com/yokitheyo/URLShortener/internal/retry"
+	"github.com/yokitheyo/URLShortener/internal/worker"
 )
 
 type AppBuilder struct {
@@ -31,11 +46,31 @@ type AppBuilder struct {
 	retryStr wbfretry.Strategy
 	dbOpts   *dbpg.Options
 
-	urlRepo      repository.URLRepository
-	urlCache     storage.Cache
-	geoIPService ports.GeoService
+	urlRepo       repository.URLRepository
+	reservedRepo  repository.ReservedWordRepository
+	apiKeyRepo    repository.APIKeyRepository
+	userRepo      repository.UserRepository
+	workspaceRepo repository.WorkspaceRepository
+	auditRepo     repository.AuditRepository
+	webhookRepo   repository.WebhookRepository
+	outboxRepo    repository.OutboxRepository
+	domainRepo    repository.DomainRepository
+	urlCache      storage.Cache
+	geoIPService  ports.GeoService
+	blocklist     safety.Blocklist
+	warnlist      safety.Blocklist
+	codeGen       codegen.Generator
+	codeFilter    *usecase.CodeFilter
+
+	background []func(ctx context.Context)
 
 	urlUseCase *usecase.URLShortenerUseCase
+	apiKeys    *usecase.APIKeyService
+	accounts   *usecase.AccountService
+	workspaces *usecase.WorkspaceService
+	audit      *usecase.AuditService
+	webhooks   *usecase.WebhookService
+	domains    *usecase.DomainService
 }
 
 func NewAppBuilder(cfg *config.Config) *AppBuilder {
@@ -131,7 +166,15 @@ func (b *AppBuilder) BuildMigrations() error {
 }
 
 func (b *AppBuilder) BuildRepositories() error {
-	b.urlRepo = repository.NewPostgresURLRepository(b.database, b.retryStr)
+	b.urlRepo = repository.NewPostgresURLRepository(b.database, b.retryStr, b.config.Events.Enabled)
+	b.reservedRepo = repository.NewPostgresReservedWordRepository(b.database)
+	b.apiKeyRepo = repository.NewPostgresAPIKeyRepository(b.database)
+	b.userRepo = repository.NewPostgresUserRepository(b.database)
+	b.workspaceRepo = repository.NewPostgresWorkspaceRepository(b.database)
+	b.auditRepo = repository.NewPostgresAuditRepository(b.database)
+	b.webhookRepo = repository.NewPostgresWebhookRepository(b.database)
+	b.outboxRepo = repository.NewPostgresOutboxRepository(b.database)
+	b.domainRepo = repository.NewPostgresDomainRepository(b.database)
 	return nil
 }
 
@@ -148,11 +191,217 @@ func (b *AppBuilder) BuildInfrastructure() error {
 	return nil
 }
 
+func (b *AppBuilder) BuildSafety() error {
+	var err error
+
+	if path := b.config.Safety.BlocklistPath; path != "" {
+		b.blocklist, err = safety.NewFileBlocklist(path, b.config.Safety.BlocklistReload)
+		if err != nil {
+			return fmt.Errorf("failed to load blocklist: %w", err)
+		}
+		b.background = append(b.background, b.blocklist.Watch)
+	}
+
+	if path := b.config.Safety.WarnlistPath; path != "" {
+		b.warnlist, err = safety.NewFileBlocklist(path, b.config.Safety.BlocklistReload)
+		if err != nil {
+			return fmt.Errorf("failed to load warnlist: %w", err)
+		}
+		b.background = append(b.background, b.warnlist.Watch)
+	}
+
+	return nil
+}
+
+func (b *AppBuilder) BuildCodeGenerator() error {
+	cfg := b.config.Shortener
+
+	switch cfg.CodeStrategy {
+	case "", codegen.StrategyRandom:
+		b.codeGen = codegen.NewRandomGenerator(cfg.CodeLength, cfg.CodeMaxLength)
+	case codegen.StrategySequence:
+		gen, err := codegen.NewSequenceGenerator(b.database, cfg.CodeAlphabet, cfg.CodeLength, cfg.CodeMaxLength)
+		if err != nil {
+			return fmt.Errorf("failed to create sequence code generator: %w", err)
+		}
+		b.codeGen = gen
+	case codegen.StrategyWords:
+		b.codeGen = codegen.NewWordsGenerator(cfg.CodeMaxLength)
+	default:
+		return fmt.Errorf("unknown code strategy %q", cfg.CodeStrategy)
+	}
+	return nil
+}
+
 func (b *AppBuilder) BuildApplicationService() error {
-	b.urlUseCase = usecase.NewURLShortenerUseCase(b.urlRepo, b.urlCache, b.geoIPService)
+	// keep untyped nils so the policy sees missing lists as nil interfaces
+	var blocklist, warnlist ports.Blocklist
+	if b.blocklist != nil {
+		blocklist = b.blocklist
+	}
+	if b.warnlist != nil {
+		warnlist = b.warnlist
+	}
+
+	policy := usecase.NewDestinationPolicy(usecase.PolicyOptions{
+		AllowDomains:      b.config.Safety.AllowDomains,
+		DenyDomains:       b.config.Safety.DenyDomains,
+		AllowPrivateHosts: b.config.Safety.AllowPrivateHosts,
+		ResolveHosts:      b.config.Safety.ResolveHosts,
+		MaxURLLength:      b.config.Safety.MaxURLLength,
+		ShortenerHosts:    b.config.Safety.ShortenerHosts,
+		WarnExtensions:    b.config.Safety.WarnExtensions,
+	}, blocklist, warnlist, net.DefaultResolver)
+
+	b.codeFilter = usecase.NewCodeFilter(b.reservedRepo, usecase.CodeFilterOptions{
+		Reserved: b.config.Shortener.ReservedWords,
+		Blocked:  b.config.Shortener.BlockedWords,
+	})
+
+	var signer *usecase.LinkSigner
+	if keys := b.config.Signing.Keys; len(keys) > 0 {
+		signingKeys := make([]usecase.SigningKey, len(keys))
+		for i, k := range keys {
+			signingKeys[i] = usecase.SigningKey{ID: k.ID, Secret: k.Secret}
+		}
+		var err error
+		signer, err = usecase.NewLinkSigner(signingKeys, b.config.Signing.ActiveKey)
+		if err != nil {
+			return fmt.Errorf("invalid signing config: %w", err)
+		}
+	}
+
+	b.urlUseCase = usecase.NewURLShortenerUseCase(b.urlRepo, b.urlCache, b.geoIPService).
+		WithDestinationPolicy(policy).
+		WithCanonicalizer(usecase.NewCanonicalizer(usecase.CanonicalOptions{
+			SortQuery:      b.config.Shortener.SortQuery,
+			StripTracking:  b.config.Shortener.StripTracking,
+			TrackingParams: b.config.Shortener.TrackingParams,
+		})).
+		WithRedirectResolver(resolver.NewHTTPResolver(nil, b.config.Safety.ResolverTimeout)).
+		// links may redirect anywhere, probes must not reach internal services
+		WithHealthProber(healthcheck.NewHTTPProber(netguard.Client(b.config.Safety.AllowPrivateHosts), b.config.Health.Timeout)).
+		WithCodeGenerator(b.codeGen).
+		WithReservedWords(b.codeFilter, b.reservedRepo).
+		WithWebhooks(b.webhookRepo).
+		WithLinkSigner(signer).
+		WithWarningSecret(b.config.Safety.WarningSecret).
+		WithDomains(b.domainRepo).
+		WithSettings(usecase.Settings{
+			BaseURL:             b.config.Shortener.BaseURL,
+			DefaultRedirectCode: b.config.Shortener.DefaultRedirectCode,
+			SelfLinkPolicy:      b.config.Safety.SelfLinkPolicy,
+			ShortenerPolicy:     b.config.Safety.ShortenerPolicy,
+			HealthFallbackAfter: b.config.Health.FallbackAfter,
+			DefaultFallbackURL:  b.config.Shortener.FallbackURL,
+			ReuseExisting:       b.config.Shortener.ReuseExisting,
+			CodeMaxAttempts:     b.config.Shortener.CodeMaxAttempts,
+			CodeGrowAfter:       b.config.Shortener.CodeGrowAfter,
+			AllowAnonymous:      b.config.Auth.AnonymousLinks,
+		})
+
+	b.apiKeys = usecase.NewAPIKeyService(b.apiKeyRepo)
+	b.accounts = usecase.NewAccountService(b.userRepo, b.workspaceRepo, usecase.AccountOptions{
+		SessionTTL:        b.config.Auth.SessionTTL,
+		AllowRegistration: b.config.Auth.AllowRegistration,
+	})
+	b.workspaces = usecase.NewWorkspaceService(b.workspaceRepo, b.userRepo)
+	b.audit = usecase.NewAuditService(b.auditRepo, b.domainRepo)
+	b.webhooks = usecase.NewWebhookService(b.webhookRepo, b.urlRepo, b.domainRepo, policy,
+		webhook.NewHTTPSender(netguard.Client(b.config.Safety.AllowPrivateHosts), b.config.Webhooks.Timeout),
+		usecase.WebhookOptions{
+			Retry: wbfretry.Strategy{
+				Attempts: b.config.Webhooks.MaxAttempts,
+				Delay:    b.config.Webhooks.RetryDelay,
+				Backoff:  b.config.Webhooks.RetryBackoff,
+			},
+		})
+	b.domains = usecase.NewDomainService(b.domainRepo,
+		domaincheck.NewHTTPVerifier(domaincheck.HTTPVerifierOptions{
+			Scheme:  b.config.Domains.VerifyScheme,
+			Port:    b.config.Domains.VerifyPort,
+			Address: b.config.Domains.VerifyAddress,
+			Timeout: b.config.Domains.VerifyTimeout,
+
+			AllowPrivate: b.config.Safety.AllowPrivateHosts,
+		}),
+		serviceHost(b.config.Shortener.BaseURL))
+	return nil
+}
+
+func (b *AppBuilder) BuildWorkers() error {
+	if b.config.Health.Enabled {
+		checker := worker.NewHealthChecker(b.urlUseCase, worker.HealthCheckerOptions{
+			Interval:        b.config.Health.Interval,
+			Concurrency:     b.config.Health.Concurrency,
+			PerHostInterval: b.config.Health.PerHostInterval,
+			BatchSize:       b.config.Health.BatchSize,
+		})
+		b.background = append(b.background, checker.Run)
+	}
+	if b.config.Webhooks.Enabled {
+		dispatcher := worker.NewWebhookDispatcher(b.webhooks, worker.WebhookDispatcherOptions{
+			Interval:    b.config.Webhooks.Interval,
+			Concurrency: b.config.Webhooks.Concurrency,
+			BatchSize:   b.config.Webhooks.BatchSize,
+		})
+		b.background = append(b.background, dispatcher.Run)
+	}
+	if b.config.Webhooks.Enabled || b.config.Events.Enabled {
+		watcher := worker.NewExpiryWatcher(b.urlUseCase, worker.ExpiryWatcherOptions{})
+		b.background = append(b.background, watcher.Run)
+	}
+	if b.config.Events.Enabled {
+		sink, err := b.buildEventSink()
+		if err != nil {
+			return err
+		}
+		relay := usecase.NewEventRelay(b.outboxRepo, sink, usecase.EventRelayOptions{
+			Retention: b.config.Events.Retention,
+		})
+		b.background = append(b.background, worker.NewOutboxRelay(relay, worker.OutboxRelayOptions{
+			Interval:  b.config.Events.Interval,
+			BatchSize: b.config.Events.BatchSize,
+		}).Run)
+	}
 	return nil
 }
 
+// serviceHost is the host of the service's own links, empty without a base URL
+func serviceHost(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+func (b *AppBuilder) buildEventSink() (ports.EventSink, error) {
+	cfg := b.config.Events
+
+	switch cfg.Sink {
+	case "", eventsink.KindRedis:
+		stream := cfg.RedisStream
+		if stream == "" {
+			stream = "link-events"
+		}
+		return eventsink.NewRedisStreamSink(&b.redisDB, stream, cfg.RedisMaxLen), nil
+	case eventsink.KindFile:
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("events.file_path is required for the file sink")
+		}
+		sink, err := eventsink.NewNDJSONFileSink(cfg.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create event sink: %w", err)
+		}
+		return sink, nil
+	case eventsink.KindStdout:
+		return eventsink.NewStdoutSink(), nil
+	default:
+		return nil, fmt.Errorf("unknown event sink %q", cfg.Sink)
+	}
+}
+
 func (b *AppBuilder) Build() (*api.API, error) {
 	if err := b.BuildRepositories(); err != nil {
 		return nil, err
@@ -162,13 +411,75 @@ func (b *AppBuilder) Build() (*api.API, error) {
 		return nil, err
 	}
 
+	if err := b.BuildSafety(); err != nil {
+		return nil, err
+	}
+
+	if err := b.BuildCodeGenerator(); err != nil {
+		return nil, err
+	}
+
 	if err := b.BuildApplicationService(); err != nil {
 		return nil, err
 	}
 
-	handler := handlers.NewURLHandler(b.urlUseCase)
+	if err := b.BuildWorkers(); err != nil {
+		return nil, err
+	}
+
+	links, err := presentationutil.NewPublicURLs(presentationutil.PublicURLOptions{
+		BaseURL:        b.config.Shortener.BaseURL,
+		DomainScheme:   b.config.Domains.Scheme,
+		TrustForwarded: b.config.Shortener.TrustForwardedHeaders,
+		TrustedProxies: b.config.Server.TrustedProxies,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid public URL settings: %w", err)
+	}
+
+	handler := handlers.NewURLHandler(b.urlUseCase, b.apiKeys, b.accounts, b.workspaces, b.audit, b.webhooks, b.domains, links)
+	auth := middleware.NewAuth(b.apiKeys, b.accounts, b.workspaces, b.config.Auth.Enabled)
+	if !b.config.Auth.Enabled {
+		zlog.Logger.Warn().Msg("auth is disabled, callers without credentials act anonymously and admin endpoints need an admin key")
+	}
 
-	apiServer := api.NewAPI(handler)
+	// clients could otherwise pick their rate limit bucket with X-Forwarded-For
+	var trustedProxies []string
+	if b.config.Shortener.TrustForwardedHeaders {
+		trustedProxies = b.config.Server.TrustedProxies
+	}
+	apiServer, err := api.NewAPI(handler, auth, b.buildRateLimiter(), trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	b.codeFilter.ReserveRoutes(apiServer.RouteWords()...)
 
 	return apiServer, nil
 }
+
+// buildRateLimiter counts requests in Redis, shared by every instance, and in
+// process while Redis is unreachable
+func (b *AppBuilder) buildRateLimiter() *middleware.RateLimiter {
+	cfg := b.config.RateLimit
+	if !cfg.Enabled {
+		return middleware.NewRateLimiter(nil, middleware.RateBudgets{})
+	}
+
+	limiter := ratelimit.NewFallbackLimiter(
+		ratelimit.NewRedisLimiter(&b.redisDB, "rl:"),
+		ratelimit.NewMemoryLimiter(),
+	)
+	return middleware.NewRateLimiter(limiter, middleware.RateBudgets{
+		Shorten:   ports.RateLimit{Requests: cfg.Shorten.Requests, Window: cfg.Shorten.Window},
+		Analytics: ports.RateLimit{Requests: cfg.Analytics.Requests, Window: cfg.Analytics.Window},
+		Redirect:  ports.RateLimit{Requests: cfg.Redirect.Requests, Window: cfg.Redirect.Window},
+		Auth:      ports.RateLimit{Requests: cfg.Auth.Requests, Window: cfg.Auth.Window},
+	})
+}
+
+// StartBackground launches long-running jobs (blocklist reloads, health checks, relays) until ctx is done
+func (b *AppBuilder) StartBackground(ctx context.Context) {
+	for _, run := range b.background {
+		go run(ctx)
+	}
+}
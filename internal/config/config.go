@@ -14,10 +14,19 @@ type Config struct {
 	Database  DatabaseConfig  `mapstructure:"database"`
 	Redis     RedisConfig     `mapstructure:"redis"`
 	Shortener ShortenerConfig `mapstructure:"shortener"`
+	Safety    SafetyConfig    `mapstructure:"safety"`
+	Health    HealthConfig    `mapstructure:"health"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Webhooks  WebhooksConfig  `mapstructure:"webhooks"`
+	Events    EventsConfig    `mapstructure:"events"`
+	Signing   SigningConfig   `mapstructure:"signing"`
+	Domains   DomainsConfig   `mapstructure:"domains"`
 }
 
 type ServerConfig struct {
-	Addr string `mapstructure:"addr"`
+	Addr           string   `mapstructure:"addr"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // IPs or CIDRs whose X-Forwarded-* headers are believed, with shortener.trust_forwarded_headers
 }
 
 type DatabaseConfig struct {
@@ -33,13 +42,134 @@ type RedisConfig struct {
 }
 
 type ShortenerConfig struct {
-	BaseURL      string        `mapstructure:"base_url"`
-	TTL          time.Duration `mapstructure:"ttl"`
-	CleanupEvery time.Duration `mapstructure:"cleanup_every"`
+	BaseURL             string        `mapstructure:"base_url"` // where clients reach the service, with the prefix path of a reverse proxy
+	TTL                 time.Duration `mapstructure:"ttl"`
+	CleanupEvery        time.Duration `mapstructure:"cleanup_every"`
+	DefaultRedirectCode int           `mapstructure:"default_redirect_code"`
+	FallbackURL         string        `mapstructure:"fallback_url"` // for expired or disabled links
+	ReuseExisting       bool          `mapstructure:"reuse_existing"`
+	SortQuery           bool          `mapstructure:"sort_query"`     // canonical URLs order query parameters
+	StripTracking       bool          `mapstructure:"strip_tracking"` // canonical URLs drop tracking parameters
+	TrackingParams      []string      `mapstructure:"tracking_params"`
+
+	TrustForwardedHeaders bool `mapstructure:"trust_forwarded_headers"` // only behind a proxy that sets or strips X-Forwarded-*
+
+	CodeStrategy    string `mapstructure:"code_strategy"`     // random, sequence or words
+	CodeLength      int    `mapstructure:"code_length"`       // random and sequence codes
+	CodeMaxLength   int    `mapstructure:"code_max_length"`   // upper bound when codes grow after collisions
+	CodeAlphabet    string `mapstructure:"code_alphabet"`     // shuffled alphabet for the sequence strategy
+	CodeMaxAttempts int    `mapstructure:"code_max_attempts"` // generated codes tried before giving up
+	CodeGrowAfter   int    `mapstructure:"code_grow_after"`   // collisions before codes get one character longer
+
+	ReservedWords []string `mapstructure:"reserved_words"` // on top of route names and the admin-managed list
+	BlockedWords  []string `mapstructure:"blocked_words"`  // offensive words on top of the built-in list
+}
+
+// SafetyConfig controls which destinations may be shortened
+type SafetyConfig struct {
+	AllowDomains      []string      `mapstructure:"allow_domains"`
+	DenyDomains       []string      `mapstructure:"deny_domains"`
+	BlocklistPath     string        `mapstructure:"blocklist_path"`
+	BlocklistReload   time.Duration `mapstructure:"blocklist_reload"`
+	WarnlistPath      string        `mapstructure:"warnlist_path"`
+	AllowPrivateHosts bool          `mapstructure:"allow_private_hosts"`
+	ResolveHosts      bool          `mapstructure:"resolve_hosts"`
+	MaxURLLength      int           `mapstructure:"max_url_length"`
+	ShortenerHosts    []string      `mapstructure:"shortener_hosts"`
+	WarnExtensions    []string      `mapstructure:"warn_extensions"`
+	ShortenerPolicy   string        `mapstructure:"shortener_policy"` // warn, reject or expand
+	SelfLinkPolicy    string        `mapstructure:"self_link_policy"` // resolve or reject
+	ResolverTimeout   time.Duration `mapstructure:"resolver_timeout"`
+	WarningSecret     string        `mapstructure:"warning_secret"` // signs warning page tickets, random per process if empty
+}
+
+// HealthConfig controls the background destination health checker
+type HealthConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Interval        time.Duration `mapstructure:"interval"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	Concurrency     int           `mapstructure:"concurrency"`
+	PerHostInterval time.Duration `mapstructure:"per_host_interval"`
+	BatchSize       int           `mapstructure:"batch_size"`
+	FallbackAfter   time.Duration `mapstructure:"fallback_after"` // 0 never redirects to fallback_url
+}
+
+// AuthConfig controls API keys, accounts and who may create links
+type AuthConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	AnonymousLinks    bool          `mapstructure:"anonymous_links"` // POST /shorten without an account or key
+	AllowRegistration bool          `mapstructure:"allow_registration"`
+	SessionTTL        time.Duration `mapstructure:"session_ttl"`
+}
+
+// RateLimitConfig sets per-client request budgets, counted per API key or
+// client IP in Redis and in process while Redis is unreachable
+type RateLimitConfig struct {
+	Enabled   bool        `mapstructure:"enabled"`
+	Shorten   LimitConfig `mapstructure:"shorten"`
+	Analytics LimitConfig `mapstructure:"analytics"` // analytics and link listings
+	Redirect  LimitConfig `mapstructure:"redirect"`  // redirects and previews
+	Auth      LimitConfig `mapstructure:"auth"`      // sign-ups and logins
+}
+
+// LimitConfig allows Requests per Window, 0 requests means no limit
+type LimitConfig struct {
+	Requests int           `mapstructure:"requests"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+// WebhooksConfig controls the webhook dispatcher and its retries
+type WebhooksConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Interval     time.Duration `mapstructure:"interval"`
+	Concurrency  int           `mapstructure:"concurrency"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxAttempts  int           `mapstructure:"max_attempts"` // failed attempts before a delivery is dead-lettered
+	RetryDelay   time.Duration `mapstructure:"retry_delay"`  // wait after the first failure
+	RetryBackoff float64       `mapstructure:"retry_backoff"`
+}
+
+// EventsConfig controls the domain event outbox and the relay publishing it
+type EventsConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Sink        string        `mapstructure:"sink"` // redis, file or stdout
+	RedisStream string        `mapstructure:"redis_stream"`
+	RedisMaxLen int64         `mapstructure:"redis_max_len"` // approximate stream length, 0 keeps every entry
+	FilePath    string        `mapstructure:"file_path"`
+	Interval    time.Duration `mapstructure:"interval"`
+	BatchSize   int           `mapstructure:"batch_size"`
+	Retention   time.Duration `mapstructure:"retention"` // how long published events stay in the outbox
+}
+
+// SigningConfig holds the key set of signed links. Every key verifies tokens,
+// ActiveKey signs new ones. No keys disables signed links.
+type SigningConfig struct {
+	ActiveKey string             `mapstructure:"active_key"`
+	Keys      []SigningKeyConfig `mapstructure:"keys"`
+}
+
+type SigningKeyConfig struct {
+	ID     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret"` // at least 32 bytes
+}
+
+// DomainsConfig controls custom domains of workspaces and how they are verified
+type DomainsConfig struct {
+	Scheme        string        `mapstructure:"scheme"`         // of short links on custom domains, https by default
+	VerifyScheme  string        `mapstructure:"verify_scheme"`  // the verification token is fetched over it, https by default
+	VerifyPort    int           `mapstructure:"verify_port"`    // 0 uses the default port of verify_scheme
+	VerifyAddress string        `mapstructure:"verify_address"` // dial this host:port instead of the domain, for local testing
+	VerifyTimeout time.Duration `mapstructure:"verify_timeout"`
 }
 
 func Load(path string) (*Config, error) {
 	c := wbfconfig.New()
+	// auth guards /admin and key issuance, leaving the key out must not open them
+	c.SetDefault("auth.enabled", true)
+	// logins check passwords, leaving the budget out must not allow guessing
+	c.SetDefault("rate_limit.auth.requests", 10)
+	c.SetDefault("rate_limit.auth.window", "1m")
 
 	if err := c.LoadConfigFiles(path); err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"net"
+	"net/netip"
+)
+
+// cgnatPrefix is shared address space (RFC 6598), not covered by net.IP.IsPrivate
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPrivateAddr reports whether addr is not on the public internet: loopback,
+// private, link-local (cloud metadata lives there), multicast, unspecified or
+// carrier-grade NAT space. The service never sends requests to such addresses.
+func IsPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	ip := net.IP(addr.AsSlice())
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || cgnatPrefix.Contains(addr)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeLinksWrite    = "links:write"    // create links
+	ScopeAnalyticsRead = "analytics:read" // read analytics and recent clicks
+	ScopeAdmin         = "admin"          // admin endpoints, implies every other scope
+)
+
+// APIKeyScopes lists every scope a key can be issued with
+var APIKeyScopes = []string{ScopeLinksWrite, ScopeAnalyticsRead, ScopeAdmin}
+
+// APIKey grants access to the management API. Only the hash of the key is stored.
+type APIKey struct {
+	ID          int64
+	Name        string
+	Prefix      string // first characters of the key, to tell keys apart
+	Hash        string // sha256 of the key, hex encoded
+	Scopes      []string
+	UserID      int64 // 0 for keys not tied to a user
+	WorkspaceID int64 // workspace requests act in, 0 to let the caller pick one
+	CreatedAt   time.Time
+	LastUsedAt  time.Time // zero if never used
+	RevokedAt   time.Time // zero while the key is active
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// HasScope reports whether the key grants scope, admin keys grant everything
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// Actor is who requests made with the key act as
+func (k *APIKey) Actor() Actor {
+	return Actor{UserID: k.UserID, KeyID: k.ID, Admin: k.HasScope(ScopeAdmin), WorkspaceID: k.WorkspaceID}
+}
+
+func IsValidScope(scope string) bool {
+	return slices.Contains(APIKeyScopes, scope)
+}
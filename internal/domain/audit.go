@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions recorded for the lifecycle of a link
+const (
+	AuditLinkCreated  = "link.created"
+	AuditLinkUpdated  = "link.updated"
+	AuditLinkDisabled = "link.disabled"
+	AuditLinkEnabled  = "link.enabled"
+	AuditLinkFlagged  = "link.risk_flag"
+	AuditLinkDeleted  = "link.deleted"
+)
+
+// AuditActions lists every action an audit event can have
+var AuditActions = []string{
+	AuditLinkCreated, AuditLinkUpdated, AuditLinkDisabled,
+	AuditLinkEnabled, AuditLinkFlagged, AuditLinkDeleted,
+}
+
+// AuditEvent records one change to a link. Events are never changed or
+// removed, and they outlive the link they describe.
+type AuditEvent struct {
+	ID          int64
+	Action      string
+	LinkID      int64
+	Short       string
+	DomainID    int64 // the link's custom domain, DefaultDomain for the service's own
+	WorkspaceID int64 // the link's workspace when the event happened
+
+	ActorUserID int64 // 0 for anonymous callers and keys without a user
+	ActorKeyID  int64 // 0 unless an API key was used
+	IP          string
+	RequestID   string
+
+	Before json.RawMessage // the link before the change, nil on creation
+	After  json.RawMessage // the link after the change, nil on deletion
+
+	CreatedAt time.Time
+}
+
+// AuditFilter narrows an audit log query, zero values match everything
+type AuditFilter struct {
+	WorkspaceID int64 // AllWorkspaces for every workspace
+	Short       string
+	DomainID    *int64 // links on this domain only, nil for every domain
+	ActorUserID int64
+	Action      string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Offset      int
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DomainVerificationPath is where a custom domain must serve its verification
+// token. The service does not answer it, so only whoever runs the domain's
+// web server can prove it owns the domain.
+const DomainVerificationPath = "/.well-known/url-shortener-verification"
+
+// DefaultDomain is the domain of links served on the service's own host
+const DefaultDomain int64 = 0
+
+// ErrDomainTaken is returned by repositories when the workspace already claimed
+// the host, or another workspace verified it first
+var ErrDomainTaken = errors.New("domain already registered")
+
+// ErrDomainInUse is returned by repositories when a domain still has links
+var ErrDomainInUse = errors.New("domain still has links")
+
+var hostLabelRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// CustomDomain is a branded host of a workspace. Its links have their own
+// codes, the same code may exist on several domains.
+type CustomDomain struct {
+	ID                int64
+	WorkspaceID       int64
+	Host              string
+	VerificationToken string
+	VerifiedAt        time.Time // zero until the token was found on the host
+	CreatedAt         time.Time
+}
+
+// Verified reports whether links may be created and served on the domain
+func (d *CustomDomain) Verified() bool {
+	return !d.VerifiedAt.IsZero()
+}
+
+// NormalizeHost lowercases host and drops a port and a trailing dot, so a
+// Host header and a registered domain compare equal
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// ValidateHost checks a normalized host name: at least two labels of
+// letters, digits and dashes, no IP addresses
+func ValidateHost(host string) error {
+	if host == "" || len(host) > 253 {
+		return errors.New("host must be 1 to 253 characters")
+	}
+	if net.ParseIP(host) != nil {
+		return errors.New("host must be a domain name, not an IP address")
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return errors.New("host must have at least two labels, e.g. go.example.com")
+	}
+	for _, label := range labels {
+		if !hostLabelRegex.MatchString(label) {
+			return errors.New("host labels can only contain letters, digits and dashes")
+		}
+	}
+	return nil
+}
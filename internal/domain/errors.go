@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+// ErrShortConflict is returned by repositories when the short code is already taken
+var ErrShortConflict = errors.New("short code already taken")
+
+// ErrEmailTaken is returned by repositories when an account with the email exists
+var ErrEmailTaken = errors.New("email already registered")
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain event types, published to the event sink through the outbox
+const (
+	EventTypeLinkCreated = "LinkCreated"
+	EventTypeLinkClicked = "LinkClicked"
+	EventTypeLinkExpired = "LinkExpired"
+)
+
+// DomainEvent is something that happened to a link. Events are stored in the
+// outbox in the transaction that made the change and published from there,
+// at least once, so consumers should drop ids they have already seen.
+type DomainEvent struct {
+	ID          int64
+	Type        string
+	LinkID      int64
+	Short       string
+	WorkspaceID int64 // 0 for links outside any workspace
+	Payload     json.RawMessage
+	OccurredAt  time.Time
+}
+
+// linkEventData is the payload of LinkCreated and LinkExpired
+type linkEventData struct {
+	Original  string `json:"original"`
+	OwnerID   int64  `json:"owner_id,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	IsCustom  bool   `json:"is_custom"`
+}
+
+// clickEventData is the payload of LinkClicked
+type clickEventData struct {
+	Location string `json:"location,omitempty"`
+	Referrer string `json:"referrer,omitempty"`
+	Device   string `json:"device,omitempty"`
+	Variant  string `json:"variant,omitempty"`
+}
+
+// NewLinkCreated describes the creation of u, which must already have its id
+func NewLinkCreated(u *URL) DomainEvent {
+	return linkEvent(EventTypeLinkCreated, u, u.CreatedAt)
+}
+
+// NewLinkExpired describes u reaching its expiry
+func NewLinkExpired(u *URL) DomainEvent {
+	return linkEvent(EventTypeLinkExpired, u, u.ExpiresAt)
+}
+
+// NewLinkClicked describes the visit c
+func NewLinkClicked(c *Click) DomainEvent {
+	payload, _ := json.Marshal(clickEventData{
+		Location: c.IP, // clicks keep the location, not the address
+		Referrer: c.Referrer,
+		Device:   c.Device,
+		Variant:  c.Variant,
+	})
+	return DomainEvent{
+		Type:        EventTypeLinkClicked,
+		LinkID:      c.URLID,
+		Short:       c.Short,
+		WorkspaceID: c.WorkspaceID,
+		Payload:     payload,
+		OccurredAt:  c.OccurredAt,
+	}
+}
+
+func linkEvent(eventType string, u *URL, at time.Time) DomainEvent {
+	payload, _ := json.Marshal(linkEventData{
+		Original:  u.Original,
+		OwnerID:   u.OwnerID,
+		CreatedAt: u.CreatedAt.Unix(),
+		ExpiresAt: u.ExpiresAt.Unix(),
+		IsCustom:  u.IsCustom,
+	})
+	return DomainEvent{
+		Type:        eventType,
+		LinkID:      u.ID,
+		Short:       u.Short,
+		WorkspaceID: u.WorkspaceID,
+		Payload:     payload,
+		OccurredAt:  at,
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Where a reserved word comes from
+const (
+	ReservedByRoute  = "route"  // first segment of an HTTP route
+	ReservedByConfig = "config" // shortener.reserved_words
+	ReservedByAdmin  = "admin"  // added through the admin API
+)
+
+// ReservedWord is a short code nobody may claim
+type ReservedWord struct {
+	Word      string
+	Source    string
+	CreatedAt time.Time // zero for route and config words
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Short code limits shared by the HTTP validation, the use cases and the
+// database (urls.short and clicks.short are VARCHAR(64), counted in characters)
+const (
+	MinShortLength = 3
+	MaxShortLength = 64
+)
+
+// letters (any script, with combining marks), digits, "_" and "-"
+var shortCodeRegex = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)
+
+// NormalizeShortCode returns the NFC form under which codes are stored and looked up,
+// so "café" typed with a combining accent finds the same link
+func NormalizeShortCode(code string) string {
+	return norm.NFC.String(code)
+}
+
+// ValidateShortCode checks a normalized custom code and explains why it is rejected
+func ValidateShortCode(code string) error {
+	n := utf8.RuneCountInString(code)
+	if n < MinShortLength || n > MaxShortLength {
+		return fmt.Errorf("short code must be between %d and %d characters, got %d", MinShortLength, MaxShortLength, n)
+	}
+	if !shortCodeRegex.MatchString(code) {
+		return errors.New("short code can only contain letters, numbers, dashes and underscores")
+	}
+	return nil
+}
+
+// IsShortCodeLength reports whether code could be a stored short code by length alone
+func IsShortCodeLength(code string) bool {
+	n := utf8.RuneCountInString(code)
+	return n > 0 && n <= MaxShortLength
+}
@@ -1,16 +1,129 @@
 package domain
 
 import (
+	"net/http"
 	"time"
 )
 
+// IsValidRedirectCode reports whether code can be used to answer /s/{short}
+func IsValidRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// IsPermanentRedirect reports whether clients may cache the redirect
+func IsPermanentRedirect(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
+
+// UTM modes decide how stored UTM tags combine with parameters already in the destination
+const (
+	UTMMerge    = "merge"    // only fill in tags the destination does not set
+	UTMOverride = "override" // always replace the destination's tags
+)
+
+// Risk flags mark destinations that are shown behind a warning page
+const (
+	RiskNewDomain      = "new_domain"
+	RiskShortenerChain = "shortener_chain"
+	RiskExecutable     = "executable_download"
+	RiskManual         = "manual"
+)
+
+// Sticky modes decide how a returning visitor is kept in the same variant
+const (
+	StickyCookie = "cookie"
+	StickyIP     = "ip"
+)
+
 type URL struct {
-	ID        int64
-	Short     string
-	Original  string
-	CreatedAt time.Time
-	ExpiresAt time.Time
-	Visits    int64
+	ID         int64
+	Short      string
+	Original   string // destination as submitted, visitors are sent here
+	Canonical  string // normalized destination used to compare links
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	Visits     int64
+	StickyMode string
+	Variants   []Variant
+
+	ForwardQuery    bool // merge the visitor's query string into the destination
+	PathPassthrough bool // append /s/{short}/{rest} to the destination path
+
+	UTM     UTM
+	UTMMode string
+
+	RedirectCode int // 0 means the server-wide default
+
+	RiskFlag          string // non-empty flags put a warning page in front of the redirect
+	WarningsShown     int64
+	WarningsProceeded int64
+
+	IsDisabled bool
+
+	Health      LinkHealth
+	FallbackURL string // used when the link is expired, disabled or broken
+
+	OriginalHash string // sha256 of the normalized destination, used to reuse links
+	IsCustom     bool
+	Signed       bool // only reachable through a signed token, never by the bare code
+
+	OwnerID     int64 // 0 for links created anonymously or before accounts existed
+	WorkspaceID int64 // 0 for links outside any workspace, e.g. anonymous ones
+	DomainID    int64 // DefaultDomain for links on the service's own host
+}
+
+// IsExpired reports whether the link stopped working at or before now
+func (u *URL) IsExpired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && !now.Before(u.ExpiresAt)
+}
+
+// LinkHealth is the outcome of the latest destination health check
+type LinkHealth struct {
+	Status      int // last HTTP status, 0 if the request failed or never ran
+	LatencyMs   int64
+	Failures    int // consecutive failed checks
+	CheckedAt   time.Time
+	BrokenSince time.Time // zero while the destination is healthy
+}
+
+// IsBroken reports whether the destination has been failing for at least d
+func (h LinkHealth) IsBroken(d time.Duration, now time.Time) bool {
+	return !h.BrokenSince.IsZero() && now.Sub(h.BrokenSince) >= d
+}
+
+// UTM holds campaign tags applied to the destination at redirect time
+type UTM struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Term     string
+	Content  string
+}
+
+// IsZero reports whether no tag is set
+func (u UTM) IsZero() bool {
+	return u == (UTM{})
+}
+
+// CampaignStats aggregates links sharing a utm_campaign
+type CampaignStats struct {
+	Campaign string
+	Links    int64
+	Visits   int64
+	Shorts   []string
+}
+
+// Variant is one weighted destination of an A/B split link
+type Variant struct {
+	ID          int64
+	URLID       int64
+	Label       string
+	Destination string
+	Weight      int
 }
 
 type Click struct {
@@ -22,4 +135,8 @@ type Click struct {
 	IP         string
 	Referrer   string
 	Device     string
+	VariantID  int64
+	Variant    string
+
+	WorkspaceID int64 // workspace of the link, set on new clicks for their event
 }
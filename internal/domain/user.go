@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+type User struct {
+	ID           int64
+	Email        string // stored lowercased
+	PasswordHash string // bcrypt
+	IsAdmin      bool
+	CreatedAt    time.Time
+}
+
+// HasScope reports what a signed-in user may do: everything but admin
+// endpoints, which need an admin account
+func (u *User) HasScope(scope string) bool {
+	return scope != ScopeAdmin || u.IsAdmin
+}
+
+// Actor is who requests made in the user's session act as
+func (u *User) Actor() Actor {
+	return Actor{UserID: u.ID, Admin: u.IsAdmin}
+}
+
+// Session is a web UI login, only the hash of its cookie value is stored
+type Session struct {
+	TokenHash string
+	UserID    int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Actor is whoever a use case runs for: a signed-in user, the user behind an
+// API key, or nobody, together with the workspace the request works in.
+// Admins may act on every link.
+type Actor struct {
+	UserID int64 // 0 for anonymous callers and keys without a user
+	KeyID  int64 // the API key the request was made with, 0 for sessions
+	Admin  bool
+
+	WorkspaceID int64  // 0 when no workspace is selected
+	Role        string // the user's role in WorkspaceID, empty for admins who are not members
+}
+
+// SystemActor runs background jobs such as health checks
+var SystemActor = Actor{Admin: true}
+
+func (a Actor) IsAnonymous() bool {
+	return a.UserID == 0 && !a.Admin
+}
+
+// InWorkspace returns a copy of the actor working in workspace id with role
+func (a Actor) InWorkspace(id int64, role string) Actor {
+	a.WorkspaceID = id
+	a.Role = role
+	return a
+}
+
+// IsMember reports whether the actor works in a workspace it belongs to
+func (a Actor) IsMember() bool {
+	return a.WorkspaceID != 0 && a.Role != ""
+}
+
+// CanAccess reports whether the actor may see the stats of u: any member of
+// the link's workspace may. Links outside workspaces are only visible to admins.
+func (a Actor) CanAccess(u *URL) bool {
+	return a.Admin || (a.IsMember() && u.WorkspaceID == a.WorkspaceID)
+}
+
+// CanWrite reports whether the actor may create links in its workspace
+func (a Actor) CanWrite() bool {
+	return a.IsMember() && CanWrite(a.Role)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook events, sent to the subscriptions of the link's workspace
+const (
+	EventLinkClicked  = "link.clicked"
+	EventLinkCreated  = "link.created"
+	EventLinkExpired  = "link.expired"
+	EventLinkDisabled = "link.disabled"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{EventLinkClicked, EventLinkCreated, EventLinkExpired, EventLinkDisabled}
+
+// Delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead" // every attempt failed, kept for inspection
+)
+
+// DeliveryStatuses lists every state a delivery can be in
+var DeliveryStatuses = []string{DeliveryPending, DeliveryDelivered, DeliveryDead}
+
+// Webhook subscribes a URL to events of one link or of a whole workspace
+type Webhook struct {
+	ID          int64
+	WorkspaceID int64
+	LinkID      int64  // 0 for every link of the workspace
+	Short       string // code of LinkID, empty without one
+	URL         string
+	Secret      string // signs the payloads with HMAC-SHA256
+	Events      []string
+	CreatedBy   int64
+	CreatedAt   time.Time
+}
+
+// WebhookDelivery is one event on its way to one webhook
+type WebhookDelivery struct {
+	ID            int64
+	WebhookID     int64
+	Event         string
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastStatus    int // HTTP status of the last attempt, 0 if the receiver did not answer
+	LastError     string
+	CreatedAt     time.Time
+	DeliveredAt   time.Time
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Workspace roles, each includes what the roles after it may do
+const (
+	RoleOwner  = "owner"  // manage members, plus everything editors do
+	RoleEditor = "editor" // create links
+	RoleViewer = "viewer" // read links and their analytics
+)
+
+// WorkspaceRoles lists every role a member can have
+var WorkspaceRoles = []string{RoleOwner, RoleEditor, RoleViewer}
+
+// AllWorkspaces lifts the workspace scope of a repository query. It is used
+// for lookups that are global by design, such as redirects, and for admins.
+const AllWorkspaces int64 = 0
+
+// Workspace is a tenant owning links, e.g. a department sharing the deployment
+type Workspace struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+// Member is a user's role in a workspace
+type Member struct {
+	WorkspaceID int64
+	UserID      int64
+	Email       string
+	Role        string
+	CreatedAt   time.Time
+}
+
+// Membership is a workspace as seen by one of its members
+type Membership struct {
+	Workspace Workspace
+	Role      string
+}
+
+func IsValidRole(role string) bool {
+	return slices.Contains(WorkspaceRoles, role)
+}
+
+// CanWrite reports whether role may create links
+func CanWrite(role string) bool {
+	return role == RoleOwner || role == RoleEditor
+}
@@ -0,0 +1,12 @@
+package codegen
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// RandomGenerator, SequenceGenerator and WordsGenerator implement ports.CodeGenerator interface
+var (
+	_ ports.CodeGenerator = (*RandomGenerator)(nil)
+	_ ports.CodeGenerator = (*SequenceGenerator)(nil)
+	_ ports.CodeGenerator = (*WordsGenerator)(nil)
+)
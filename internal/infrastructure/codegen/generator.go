@@ -0,0 +1,51 @@
+package codegen
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// Strategies selectable in config
+const (
+	StrategyRandom   = "random"
+	StrategySequence = "sequence"
+	StrategyWords    = "words"
+)
+
+const (
+	defaultLength    = 6
+	defaultMaxLength = 10
+)
+
+type Generator interface {
+	Generate(ctx context.Context, grow int) (string, error)
+}
+
+// clampLength returns length+grow limited to maxLength
+func clampLength(length, grow, maxLength int) int {
+	n := length + grow
+	if n > maxLength {
+		n = maxLength
+	}
+	return n
+}
+
+func normalizeLengths(length, maxLength int) (int, int) {
+	if length <= 0 {
+		length = defaultLength
+	}
+	if maxLength <= 0 {
+		maxLength = defaultMaxLength
+	}
+	if length > domain.MaxShortLength {
+		length = domain.MaxShortLength
+	}
+	if maxLength > domain.MaxShortLength {
+		maxLength = domain.MaxShortLength
+	}
+	if maxLength < length {
+		maxLength = length
+	}
+	return length, maxLength
+}
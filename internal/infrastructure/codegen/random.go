@@ -0,0 +1,47 @@
+package codegen
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// RandomGenerator draws codes of uniformly random base62 characters
+type RandomGenerator struct {
+	length    int
+	maxLength int
+}
+
+func NewRandomGenerator(length, maxLength int) Generator {
+	length, maxLength = normalizeLengths(length, maxLength)
+	return &RandomGenerator{length: length, maxLength: maxLength}
+}
+
+func (g *RandomGenerator) Generate(_ context.Context, grow int) (string, error) {
+	return randomString(base62Alphabet, clampLength(g.length, grow, g.maxLength))
+}
+
+// randomString picks n characters from alphabet, rejecting bytes that would bias the result
+func randomString(alphabet string, n int) (string, error) {
+	limit := 256 - 256%len(alphabet)
+	out := make([]byte, 0, n)
+	buf := make([]byte, n+n/2)
+
+	for len(out) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("read random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			out = append(out, alphabet[int(b)%len(alphabet)])
+			if len(out) == n {
+				break
+			}
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,111 @@
+package codegen
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+
+	"github.com/wb-go/wbf/dbpg"
+)
+
+// defaultSequenceAlphabet is base62 in a fixed shuffled order, so codes cannot
+// be decoded back to sequence values with the standard alphabet
+const defaultSequenceAlphabet = "rWlnZIYDjua1qoyO83FtSfzwhpBC07Gx9VX5EPvUJdLHKMbAgc2sTiN46kmRQe"
+
+// scrambleMultiplier is a prime larger than any alphabet size, so multiplying
+// by it modulo len(alphabet)^n permutes the n-character code space
+const scrambleMultiplier = 1580030173
+
+// SequenceGenerator encodes values of the short_code_seq Postgres sequence with
+// a shuffled alphabet. Values are scrambled within the code space of their
+// length so neighbouring values do not produce similar codes. Codes are unique
+// by construction, collisions can only happen with custom codes.
+type SequenceGenerator struct {
+	db        *dbpg.DB
+	alphabet  string
+	length    int
+	maxLength int
+}
+
+// NewSequenceGenerator uses alphabet (defaultSequenceAlphabet if empty), which must
+// hold unique URL-safe characters
+func NewSequenceGenerator(db *dbpg.DB, alphabet string, length, maxLength int) (Generator, error) {
+	if alphabet == "" {
+		alphabet = defaultSequenceAlphabet
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	length, maxLength = normalizeLengths(length, maxLength)
+	return &SequenceGenerator{
+		db:        db,
+		alphabet:  alphabet,
+		length:    length,
+		maxLength: maxLength,
+	}, nil
+}
+
+func (g *SequenceGenerator) Generate(ctx context.Context, grow int) (string, error) {
+	var n int64
+	if err := g.db.QueryRowContext(ctx, `SELECT nextval('short_code_seq')`).Scan(&n); err != nil {
+		return "", fmt.Errorf("next sequence value: %w", err)
+	}
+	if n < 0 {
+		return "", fmt.Errorf("negative sequence value %d", n)
+	}
+	return encode(uint64(n), g.alphabet, clampLength(g.length, grow, g.maxLength)), nil
+}
+
+// encode writes n in base len(alphabet) using the shortest width of at least
+// minLength that fits n. Within one width n is scrambled by a bijection, codes
+// of different widths differ in length, so distinct values give distinct codes.
+func encode(n uint64, alphabet string, minLength int) string {
+	base := uint64(len(alphabet))
+
+	width, space, fits := 0, uint64(1), true
+	for width < minLength || space <= n {
+		if space > math.MaxUint64/base {
+			fits = false
+			break
+		}
+		space *= base
+		width++
+	}
+	if fits {
+		hi, lo := bits.Mul64(n, scrambleMultiplier)
+		n = bits.Rem64(hi, lo, space)
+	}
+
+	buf := make([]byte, 0, width)
+	for n > 0 {
+		buf = append(buf, alphabet[n%base])
+		n /= base
+	}
+	for len(buf) < width {
+		buf = append(buf, alphabet[0])
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 16 {
+		return fmt.Errorf("code alphabet must have at least 16 characters")
+	}
+	seen := make(map[rune]struct{}, len(alphabet))
+	for _, r := range alphabet {
+		if !strings.ContainsRune(base62Alphabet+"_-", r) {
+			return fmt.Errorf("code alphabet contains %q, only letters, digits, _ and - are allowed", r)
+		}
+		if _, ok := seen[r]; ok {
+			return fmt.Errorf("code alphabet contains %q more than once", r)
+		}
+		seen[r] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package codegen
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+const (
+	defaultWordDigits = 2
+	digitAlphabet     = "0123456789"
+)
+
+var (
+	adjectives = []string{
+		"able", "airy", "bold", "brave", "calm", "cool", "cozy", "deep", "easy", "epic",
+		"fair", "fast", "fine", "fond", "free", "glad", "gold", "good", "grand", "happy",
+		"keen", "kind", "late", "lazy", "lime", "loud", "lush", "mild", "mint", "neat",
+		"nice", "noble", "odd", "pink", "plum", "proud", "pure", "quick", "quiet", "rare",
+		"real", "rich", "ripe", "rosy", "ruby", "safe", "shy", "silky", "slim", "smart",
+		"snug", "soft", "sunny", "swift", "tall", "tidy", "tiny", "true", "vast", "warm",
+		"wild", "wise", "witty", "young",
+	}
+	nouns = []string{
+		"ant", "bear", "bee", "bird", "boat", "cat", "cloud", "crab", "deer", "dove",
+		"duck", "eagle", "elk", "fern", "fish", "fox", "frog", "goat", "hawk", "hill",
+		"iris", "jay", "kite", "koala", "lake", "lark", "leaf", "lily", "lion", "lynx",
+		"moon", "moss", "moth", "newt", "oak", "otter", "owl", "panda", "pear", "pine",
+		"plum", "pond", "puma", "rain", "reef", "river", "robin", "rose", "sage", "seal",
+		"shell", "sky", "snow", "star", "stone", "swan", "tiger", "toad", "tree", "tulip",
+		"wave", "whale", "wolf", "wren",
+	}
+)
+
+// WordsGenerator builds readable codes such as "calmfox42": an adjective, a noun
+// and a few digits. grow adds digits; codes never exceed maxLength.
+type WordsGenerator struct {
+	digits    int
+	maxLength int
+}
+
+func NewWordsGenerator(maxLength int) Generator {
+	_, maxLength = normalizeLengths(0, maxLength)
+	return &WordsGenerator{digits: defaultWordDigits, maxLength: maxLength}
+}
+
+func (g *WordsGenerator) Generate(_ context.Context, grow int) (string, error) {
+	digits := g.digits + grow
+
+	// retry with other words when the pair is too long for maxLength
+	for i := 0; i < 10; i++ {
+		adj, err := pick(adjectives)
+		if err != nil {
+			return "", err
+		}
+		noun, err := pick(nouns)
+		if err != nil {
+			return "", err
+		}
+
+		room := g.maxLength - len(adj) - len(noun)
+		if room <= 0 {
+			continue
+		}
+		n := digits
+		if n > room {
+			n = room
+		}
+
+		suffix, err := randomString(digitAlphabet, n)
+		if err != nil {
+			return "", err
+		}
+		return adj + noun + suffix, nil
+	}
+	return "", fmt.Errorf("no word pair fits into %d characters", g.maxLength)
+}
+
+func pick(words []string) (string, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(words))))
+	if err != nil {
+		return "", fmt.Errorf("pick word: %w", err)
+	}
+	return words[i.Int64()], nil
+}
@@ -12,9 +12,9 @@ func MigrateDB(db *dbpg.DB) error {
 	return nil
 }
 
-func NewPostgresRepository(db *dbpg.DB, strategy wbfretry.Strategy) repository.URLRepository {
+func NewPostgresRepository(db *dbpg.DB, strategy wbfretry.Strategy, outbox bool) repository.URLRepository {
 	if strategy.Attempts <= 0 {
 		strategy = retry.DefaultStrategy
 	}
-	return repository.NewPostgresURLRepository(db, strategy)
+	return repository.NewPostgresURLRepository(db, strategy, outbox)
 }
@@ -0,0 +1,8 @@
+package domaincheck
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// HTTPVerifier implements ports.DomainVerifier interface
+var _ ports.DomainVerifier = (*HTTPVerifier)(nil)
@@ -0,0 +1,101 @@
+package domaincheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/netguard"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	maxRedirects   = 3
+	maxTokenBytes  = 1 << 10
+	userAgent      = "URLShortener-DomainCheck/1.0"
+)
+
+// HTTPVerifierOptions tune the verifier, zero values fall back to defaults
+type HTTPVerifierOptions struct {
+	Scheme  string // "https" by default, "http" for local setups
+	Port    int    // 0 uses the default port of the scheme
+	Address string // dial this host:port instead of resolving the domain, for local testing
+	Timeout time.Duration
+
+	AllowPrivate bool // let the domain, or its redirects, resolve to private addresses
+}
+
+// HTTPVerifier reads the verification token a domain serves over HTTP
+type HTTPVerifier struct {
+	client *http.Client
+	opts   HTTPVerifierOptions
+}
+
+func NewHTTPVerifier(opts HTTPVerifierOptions) Verifier {
+	if opts.Scheme == "" {
+		opts.Scheme = "https"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	// the host is chosen by users, so without Address every dial is vetted
+	transport := netguard.Transport()
+	if opts.AllowPrivate {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if opts.Address != "" {
+		var dialer net.Dialer
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, opts.Address)
+		}
+	}
+
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			// via holds the original request too
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+	return &HTTPVerifier{client: client, opts: opts}
+}
+
+// FetchToken returns the trimmed body host serves at domain.DomainVerificationPath
+func (v *HTTPVerifier) FetchToken(ctx context.Context, host string) (string, error) {
+	authority := host
+	if v.opts.Port > 0 {
+		authority = net.JoinHostPort(host, fmt.Sprint(v.opts.Port))
+	}
+	target := v.opts.Scheme + "://" + authority + domain.DomainVerificationPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s answered %d", target, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenBytes))
+	if err != nil {
+		return "", fmt.Errorf("read token: %w", err)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
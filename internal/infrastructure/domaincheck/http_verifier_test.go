@@ -0,0 +1,131 @@
+package domaincheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/netguard"
+)
+
+func newDomainServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// localVerifier dials srv for every domain, the way verify_address does
+func localVerifier(srv *httptest.Server, port int) Verifier {
+	return NewHTTPVerifier(HTTPVerifierOptions{Scheme: "http", Port: port, Address: srv.Listener.Addr().String()})
+}
+
+func TestFetchTokenReadsWellKnownPath(t *testing.T) {
+	srv := newDomainServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "go.shop.example" {
+			t.Errorf("Host = %q, want go.shop.example", r.Host)
+		}
+		if r.URL.Path != domain.DomainVerificationPath {
+			t.Errorf("path = %q, want %s", r.URL.Path, domain.DomainVerificationPath)
+		}
+		w.Write([]byte("  token-123\n"))
+	})
+
+	token, err := localVerifier(srv, 0).FetchToken(context.Background(), "go.shop.example")
+	if err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+	if token != "token-123" {
+		t.Errorf("FetchToken = %q, want token-123", token)
+	}
+}
+
+func TestFetchTokenUsesPort(t *testing.T) {
+	srv := newDomainServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "go.shop.example:8443" {
+			t.Errorf("Host = %q, want go.shop.example:8443", r.Host)
+		}
+		w.Write([]byte("token"))
+	})
+
+	if _, err := localVerifier(srv, 8443).FetchToken(context.Background(), "go.shop.example"); err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+}
+
+func TestFetchTokenFollowsFewRedirects(t *testing.T) {
+	tests := []struct {
+		name      string
+		redirects int
+		wantErr   bool
+	}{
+		{name: "within limit", redirects: maxRedirects},
+		{name: "too many", redirects: maxRedirects + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hops := 0
+			srv := newDomainServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if hops < tt.redirects {
+					hops++
+					http.Redirect(w, r, domain.DomainVerificationPath+"/"+strings.Repeat("x", hops), http.StatusFound)
+					return
+				}
+				w.Write([]byte("token"))
+			})
+
+			token, err := localVerifier(srv, 0).FetchToken(context.Background(), "go.shop.example")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FetchToken = %q, want an error", token)
+				}
+				return
+			}
+			if err != nil || token != "token" {
+				t.Fatalf("FetchToken = %q, %v, want token", token, err)
+			}
+		})
+	}
+}
+
+func TestFetchTokenRejectsBadAnswers(t *testing.T) {
+	srv := newDomainServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	if _, err := localVerifier(srv, 0).FetchToken(context.Background(), "go.shop.example"); err == nil {
+		t.Fatal("FetchToken succeeded on a 404")
+	}
+}
+
+func TestFetchTokenLimitsBody(t *testing.T) {
+	srv := newDomainServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 4*maxTokenBytes)))
+	})
+
+	token, err := localVerifier(srv, 0).FetchToken(context.Background(), "go.shop.example")
+	if err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+	if len(token) != maxTokenBytes {
+		t.Errorf("read %d bytes, want %d", len(token), maxTokenBytes)
+	}
+}
+
+func TestFetchTokenRefusesPrivateAddresses(t *testing.T) {
+	srv := newDomainServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("verification reached a loopback server")
+	})
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	v := NewHTTPVerifier(HTTPVerifierOptions{Scheme: "http"})
+	_, err := v.FetchToken(context.Background(), net.JoinHostPort("127.0.0.1", port))
+	if !errors.Is(err, netguard.ErrPrivateAddress) {
+		t.Fatalf("FetchToken error = %v, want ErrPrivateAddress", err)
+	}
+}
@@ -0,0 +1,9 @@
+package domaincheck
+
+import (
+	"context"
+)
+
+type Verifier interface {
+	FetchToken(ctx context.Context, host string) (string, error)
+}
@@ -0,0 +1,11 @@
+package eventsink
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// RedisStreamSink implements ports.EventSink interface
+var _ ports.EventSink = (*RedisStreamSink)(nil)
+
+// WriterSink implements ports.EventSink interface
+var _ ports.EventSink = (*WriterSink)(nil)
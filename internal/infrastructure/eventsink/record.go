@@ -0,0 +1,36 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// record is the published form of an event, shared by every sink. Consumers
+// use id to drop events they have seen, delivery is at least once.
+type record struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	LinkID      int64           `json:"link_id"`
+	Short       string          `json:"short"`
+	WorkspaceID int64           `json:"workspace_id,omitempty"`
+	OccurredAt  string          `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func newRecord(e *domain.DomainEvent) record {
+	data := e.Payload
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return record{
+		ID:          e.ID,
+		Type:        e.Type,
+		LinkID:      e.LinkID,
+		Short:       e.Short,
+		WorkspaceID: e.WorkspaceID,
+		OccurredAt:  e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Data:        data,
+	}
+}
@@ -0,0 +1,48 @@
+package eventsink
+
+import (
+	"context"
+	"strconv"
+
+	wbfredis "github.com/wb-go/wbf/redis"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// RedisStreamSink appends events to a Redis stream, one entry per event with
+// the fields of record. The stream is trimmed to about maxLen entries, 0 keeps
+// every entry.
+type RedisStreamSink struct {
+	client *wbfredis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamSink(client *wbfredis.Client, stream string, maxLen int64) Sink {
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+// Publish sends the batch in one pipeline, a retried batch adds its entries again
+func (s *RedisStreamSink) Publish(ctx context.Context, events []*domain.DomainEvent) error {
+	pipe := s.client.Pipeline()
+	for _, e := range events {
+		r := newRecord(e)
+
+		args := []interface{}{"XADD", s.stream}
+		if s.maxLen > 0 {
+			args = append(args, "MAXLEN", "~", s.maxLen)
+		}
+		args = append(args, "*",
+			"id", strconv.FormatInt(r.ID, 10),
+			"type", r.Type,
+			"link_id", strconv.FormatInt(r.LinkID, 10),
+			"short", r.Short,
+			"workspace_id", strconv.FormatInt(r.WorkspaceID, 10),
+			"occurred_at", r.OccurredAt,
+			"data", string(r.Data),
+		)
+		pipe.Do(ctx, args...)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
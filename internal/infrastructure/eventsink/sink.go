@@ -0,0 +1,18 @@
+package eventsink
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type Sink interface {
+	Publish(ctx context.Context, events []*domain.DomainEvent) error
+}
+
+// Sink kinds selectable in the configuration
+const (
+	KindRedis  = "redis"
+	KindFile   = "file"
+	KindStdout = "stdout"
+)
@@ -0,0 +1,63 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// WriterSink writes events as newline-delimited JSON, one record per line
+type WriterSink struct {
+	mu   sync.Mutex
+	w    io.Writer
+	sync func() error // flushes the batch to stable storage, nil when there is nothing to flush
+}
+
+// NewNDJSONFileSink appends events to the file at path, creating it if needed.
+// Each batch is synced to disk before it counts as published.
+func NewNDJSONFileSink(path string) (Sink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event file: %w", err)
+	}
+	return &WriterSink{w: f, sync: f.Sync}, nil
+}
+
+// NewStdoutSink prints events to standard output, for development and for
+// log shippers reading the container output
+func NewStdoutSink() Sink {
+	return &WriterSink{w: os.Stdout}
+}
+
+// Publish writes the batch with a single write, so concurrent relays in one
+// process never interleave their lines
+func (s *WriterSink) Publish(ctx context.Context, events []*domain.DomainEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(newRecord(e)); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if s.sync != nil {
+		return s.sync()
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package healthcheck
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// HTTPProber implements ports.HealthProber interface
+var _ ports.HealthProber = (*HTTPProber)(nil)
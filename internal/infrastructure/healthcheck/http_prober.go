@@ -0,0 +1,72 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	maxRedirects   = 5
+	userAgent      = "URLShortener-HealthCheck/1.0"
+	maxDrainBytes  = 64 << 10
+)
+
+// HTTPProber checks destinations with a HEAD request and falls back to GET
+// for servers that refuse HEAD. Redirects are followed up to maxRedirects,
+// a client built by netguard keeps every hop off private networks.
+type HTTPProber struct {
+	client *http.Client
+}
+
+func NewHTTPProber(client *http.Client, timeout time.Duration) Prober {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	var c http.Client
+	if client != nil {
+		c = *client
+	}
+	if c.Timeout == 0 {
+		c.Timeout = timeout
+	}
+	c.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+
+	return &HTTPProber{client: &c}
+}
+
+func (p *HTTPProber) Probe(ctx context.Context, rawURL string) (int, time.Duration, error) {
+	start := time.Now()
+
+	status, err := p.do(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = p.do(ctx, http.MethodGet, rawURL)
+	}
+	return status, time.Since(start), err
+}
+
+func (p *HTTPProber) do(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,10 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+)
+
+type Prober interface {
+	Probe(ctx context.Context, rawURL string) (int, time.Duration, error)
+}
@@ -0,0 +1,60 @@
+// Package netguard keeps outgoing requests of the service off private networks.
+// The check runs when a connection is dialed, on the address the host name
+// resolved to right then, so redirects and DNS rebinding cannot slip past it.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	keepAlive   = 30 * time.Second
+)
+
+// ErrPrivateAddress is returned when a connection to a non-public address is refused
+var ErrPrivateAddress = errors.New("refusing to connect to a private address")
+
+// Control is a net.Dialer.Control that refuses connections to private,
+// loopback and link-local addresses
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	if domain.IsPrivateAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	return nil
+}
+
+// Dialer returns a dialer guarded by Control
+func Dialer() *net.Dialer {
+	return &net.Dialer{Timeout: dialTimeout, KeepAlive: keepAlive, Control: Control}
+}
+
+// Transport returns a copy of http.DefaultTransport that dials through
+// Dialer. It ignores proxy settings, a proxy would dial in its stead.
+func Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = Dialer().DialContext
+	return t
+}
+
+// Client returns a client using Transport, or nil when allowPrivate is set so
+// callers fall back to their default client
+func Client(allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return nil
+	}
+	return &http.Client{Transport: Transport()}
+}
@@ -0,0 +1,14 @@
+package ratelimit
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// RedisLimiter implements ports.RateLimiter interface
+var _ ports.RateLimiter = (*RedisLimiter)(nil)
+
+// MemoryLimiter implements ports.RateLimiter interface
+var _ ports.RateLimiter = (*MemoryLimiter)(nil)
+
+// FallbackLimiter implements ports.RateLimiter interface
+var _ ports.RateLimiter = (*FallbackLimiter)(nil)
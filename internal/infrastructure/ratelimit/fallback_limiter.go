@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+const (
+	defaultPrimaryTimeout = 200 * time.Millisecond
+	defaultCooldown       = 10 * time.Second
+)
+
+// FallbackLimiter asks primary and switches to fallback when primary fails.
+// After a failure primary is skipped for a cooldown, so an outage does not
+// add a timeout to every request.
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	timeout  time.Duration
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func NewFallbackLimiter(primary, fallback Limiter) Limiter {
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+		timeout:  defaultPrimaryTimeout,
+		cooldown: defaultCooldown,
+		now:      time.Now,
+	}
+}
+
+func (f *FallbackLimiter) Allow(ctx context.Context, key string, limit ports.RateLimit) (ports.RateDecision, error) {
+	if f.primaryUp() {
+		pctx, cancel := context.WithTimeout(ctx, f.timeout)
+		decision, err := f.primary.Allow(pctx, key, limit)
+		cancel()
+		if err == nil {
+			return decision, nil
+		}
+		// a client that went away says nothing about the primary
+		if ctx.Err() == nil {
+			f.markDown(err)
+		}
+	}
+	return f.fallback.Allow(ctx, key, limit)
+}
+
+func (f *FallbackLimiter) primaryUp() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now().After(f.downUntil)
+}
+
+func (f *FallbackLimiter) markDown(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if now.After(f.downUntil) {
+		zlog.Logger.Warn().Err(err).Dur("cooldown", f.cooldown).Msg("rate limiter unavailable, counting in process")
+	}
+	f.downUntil = now.Add(f.cooldown)
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// stubLimiter fails with err, or allows with remaining to tell it apart
+type stubLimiter struct {
+	remaining int
+	err       error
+	calls     int
+}
+
+func (s *stubLimiter) Allow(context.Context, string, ports.RateLimit) (ports.RateDecision, error) {
+	s.calls++
+	if s.err != nil {
+		return ports.RateDecision{}, s.err
+	}
+	return ports.RateDecision{Allowed: true, Remaining: s.remaining}, nil
+}
+
+func TestFallbackLimiterCooldown(t *testing.T) {
+	const primaryAnswer, fallbackAnswer = 1, 2
+	primary := &stubLimiter{remaining: primaryAnswer}
+	fallback := &stubLimiter{remaining: fallbackAnswer}
+	c := &clock{t: time.Unix(1760000000, 0)}
+	f := &FallbackLimiter{primary: primary, fallback: fallback, timeout: time.Second, cooldown: 10 * time.Second, now: c.now}
+
+	tests := []struct {
+		name        string
+		advance     time.Duration
+		primaryErr  error
+		want        int
+		wantPrimary bool
+	}{
+		{name: "primary up", want: primaryAnswer, wantPrimary: true},
+		{name: "primary fails", primaryErr: errors.New("redis: connection refused"), want: fallbackAnswer, wantPrimary: true},
+		{name: "during cooldown", advance: 5 * time.Second, want: fallbackAnswer},
+		{name: "end of cooldown", advance: 5 * time.Second, want: fallbackAnswer},
+		{name: "after cooldown", advance: time.Millisecond, want: primaryAnswer, wantPrimary: true},
+	}
+	for _, tt := range tests {
+		c.advance(tt.advance)
+		primary.err = tt.primaryErr
+		calls := primary.calls
+
+		got, err := f.Allow(context.Background(), "ip:1", ports.RateLimit{Requests: 1, Window: time.Minute})
+		if err != nil {
+			t.Fatalf("%s: Allow: %v", tt.name, err)
+		}
+		if got.Remaining != tt.want {
+			t.Errorf("%s: answered by %d, want %d", tt.name, got.Remaining, tt.want)
+		}
+		if asked := primary.calls > calls; asked != tt.wantPrimary {
+			t.Errorf("%s: primary asked = %v, want %v", tt.name, asked, tt.wantPrimary)
+		}
+	}
+}
+
+func TestFallbackLimiterIgnoresCanceledClient(t *testing.T) {
+	primary := &stubLimiter{err: context.Canceled}
+	fallback := &stubLimiter{}
+	f := NewFallbackLimiter(primary, fallback)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f.Allow(ctx, "ip:1", ports.RateLimit{Requests: 1, Window: time.Minute})
+
+	primary.err = nil
+	f.Allow(context.Background(), "ip:1", ports.RateLimit{Requests: 1, Window: time.Minute})
+	if primary.calls != 2 {
+		t.Errorf("primary asked %d times, want it kept up after a canceled request", primary.calls)
+	}
+}
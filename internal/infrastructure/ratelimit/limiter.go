@@ -0,0 +1,11 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit ports.RateLimit) (ports.RateDecision, error)
+}
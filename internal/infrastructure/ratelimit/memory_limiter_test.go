@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// clock is a settable time source for the limiters
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestMemoryLimiterRefill(t *testing.T) {
+	limit := ports.RateLimit{Requests: 3, Window: 3 * time.Second}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    ports.RateDecision
+	}{
+		{name: "first", want: ports.RateDecision{Allowed: true, Remaining: 2, Reset: time.Second}},
+		{name: "second", want: ports.RateDecision{Allowed: true, Remaining: 1, Reset: 2 * time.Second}},
+		{name: "last token", want: ports.RateDecision{Allowed: true, Remaining: 0, Reset: 3 * time.Second}},
+		{name: "empty", want: ports.RateDecision{Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}},
+		{name: "half a token later", advance: 500 * time.Millisecond, want: ports.RateDecision{Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{name: "one token refilled", advance: 500 * time.Millisecond, want: ports.RateDecision{Allowed: true, Remaining: 0, Reset: 3 * time.Second}},
+		{name: "refilled past capacity", advance: time.Hour, want: ports.RateDecision{Allowed: true, Remaining: 2, Reset: time.Second}},
+	}
+
+	c := &clock{t: time.Unix(1760000000, 0)}
+	m := &MemoryLimiter{now: c.now, buckets: make(map[string]*bucket)}
+	for _, tt := range tests {
+		c.advance(tt.advance)
+		got, err := m.Allow(context.Background(), "ip:1", limit)
+		if err != nil {
+			t.Fatalf("%s: Allow: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryLimiterKeysAreSeparate(t *testing.T) {
+	limit := ports.RateLimit{Requests: 1, Window: time.Minute}
+	m := NewMemoryLimiter()
+
+	if d, _ := m.Allow(context.Background(), "ip:1", limit); !d.Allowed {
+		t.Fatal("first request denied")
+	}
+	if d, _ := m.Allow(context.Background(), "ip:1", limit); d.Allowed {
+		t.Error("second request on the same key allowed")
+	}
+	if d, _ := m.Allow(context.Background(), "ip:2", limit); !d.Allowed {
+		t.Error("another key shares the bucket")
+	}
+}
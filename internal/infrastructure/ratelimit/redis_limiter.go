@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	wbfredis "github.com/wb-go/wbf/redis"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// slidingWindowScript keeps the timestamps of the requests in the last window
+// in a sorted set. It reads the clock from Redis so every app instance agrees.
+// Returns {allowed, remaining, ms until the oldest request leaves the window,
+// ms until the newest one does}.
+const slidingWindowScript = `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local retry, reset = 0, 0
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+	reset = tonumber(newest[2]) + window - now
+end
+return {allowed, limit - count, retry, reset}
+`
+
+// RedisLimiter is a sliding window limiter shared by all app instances.
+// Every request in the window is stored, so keep budgets in the thousands at most.
+type RedisLimiter struct {
+	client *wbfredis.Client
+	prefix string
+}
+
+func NewRedisLimiter(client *wbfredis.Client, prefix string) Limiter {
+	return &RedisLimiter{client: client, prefix: prefix}
+}
+
+func (r *RedisLimiter) Allow(ctx context.Context, key string, limit ports.RateLimit) (ports.RateDecision, error) {
+	member, err := requestID()
+	if err != nil {
+		return ports.RateDecision{}, err
+	}
+
+	res, err := r.client.Eval(ctx, slidingWindowScript, []string{r.prefix + key},
+		limit.Requests, limit.Window.Milliseconds(), member).Result()
+	if err != nil {
+		return ports.RateDecision{}, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 4 {
+		return ports.RateDecision{}, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryMs, _ := values[2].(int64)
+	resetMs, _ := values[3].(int64)
+
+	decision := ports.RateDecision{
+		Allowed:   allowed == 1,
+		Remaining: int(remaining),
+		Reset:     time.Duration(resetMs) * time.Millisecond,
+	}
+	if !decision.Allowed {
+		decision.RetryAfter = time.Duration(retryMs) * time.Millisecond
+	}
+	return decision, nil
+}
+
+// requestID tells apart requests counted in the same millisecond
+func requestID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -6,3 +6,27 @@ import (
 
 // PostgresURLRepository implements ports.URLRepository interface
 var _ ports.URLRepository = (*PostgresURLRepository)(nil)
+
+// PostgresReservedWordRepository implements ports.ReservedWordRepository interface
+var _ ports.ReservedWordRepository = (*PostgresReservedWordRepository)(nil)
+
+// PostgresAPIKeyRepository implements ports.APIKeyRepository interface
+var _ ports.APIKeyRepository = (*PostgresAPIKeyRepository)(nil)
+
+// PostgresUserRepository implements ports.UserRepository interface
+var _ ports.UserRepository = (*PostgresUserRepository)(nil)
+
+// PostgresWorkspaceRepository implements ports.WorkspaceRepository interface
+var _ ports.WorkspaceRepository = (*PostgresWorkspaceRepository)(nil)
+
+// PostgresAuditRepository implements ports.AuditRepository interface
+var _ ports.AuditRepository = (*PostgresAuditRepository)(nil)
+
+// PostgresWebhookRepository implements ports.WebhookRepository interface
+var _ ports.WebhookRepository = (*PostgresWebhookRepository)(nil)
+
+// PostgresOutboxRepository implements ports.OutboxRepository interface
+var _ ports.OutboxRepository = (*PostgresOutboxRepository)(nil)
+
+// PostgresDomainRepository implements ports.DomainRepository interface
+var _ ports.DomainRepository = (*PostgresDomainRepository)(nil)
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type AuditRepository interface {
+	List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type DomainRepository interface {
+	Create(ctx context.Context, d *domain.CustomDomain) error
+	FindByID(ctx context.Context, workspaceID, id int64) (*domain.CustomDomain, error)
+	FindByHost(ctx context.Context, workspaceID int64, host string) (*domain.CustomDomain, error)
+	List(ctx context.Context, workspaceID int64) ([]*domain.CustomDomain, error)
+	MarkVerified(ctx context.Context, id int64, at time.Time) error
+	Delete(ctx context.Context, workspaceID, id int64) (bool, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type OutboxRepository interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.DomainEvent, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
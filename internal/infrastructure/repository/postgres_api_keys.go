@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, user_id, workspace_id, created_at, last_used_at, revoked_at`
+
+// PostgresAPIKeyRepository keeps API keys in api_keys, only their sha256 hashes are stored
+type PostgresAPIKeyRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresAPIKeyRepository(db *dbpg.DB) APIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db}
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var k domain.APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+	var userID, workspaceID sql.NullInt64
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, pq.Array(&k.Scopes), &userID, &workspaceID, &k.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	k.UserID = userID.Int64
+	k.WorkspaceID = workspaceID.Int64
+	k.LastUsedAt = lastUsedAt.Time
+	k.RevokedAt = revokedAt.Time
+	return &k, nil
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	q := `INSERT INTO api_keys (name, prefix, key_hash, scopes, user_id, workspace_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	return r.db.QueryRowContext(ctx, q, key.Name, key.Prefix, key.Hash, pq.Array(key.Scopes),
+		nullID(key.UserID), nullID(key.WorkspaceID), key.CreatedAt).Scan(&key.ID)
+}
+
+func (r *PostgresAPIKeyRepository) FindByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	q := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, q, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	q := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) (bool, error) {
+	q := `UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, q, id, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	q := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, q, id, at)
+	return err
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const auditColumns = `id, action, link_id, short, domain_id, workspace_id, actor_user_id, actor_key_id,
+	ip, request_id, before, after, created_at`
+
+// PostgresAuditRepository reads the audit log written by the other repositories
+type PostgresAuditRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresAuditRepository(db *dbpg.DB) AuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+// insertAuditEvent appends e inside tx, so the event exists exactly when the change does
+func insertAuditEvent(ctx context.Context, tx *sql.Tx, e *domain.AuditEvent) error {
+	q := `INSERT INTO audit_events (action, link_id, short, domain_id, workspace_id, actor_user_id, actor_key_id,
+		  ip, request_id, before, after, created_at)
+		  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		  RETURNING id`
+
+	return tx.QueryRowContext(ctx, q, e.Action, e.LinkID, e.Short, nullID(e.DomainID), nullID(e.WorkspaceID),
+		nullID(e.ActorUserID), nullID(e.ActorKeyID), e.IP, e.RequestID,
+		nullJSON(e.Before), nullJSON(e.After), e.CreatedAt).Scan(&e.ID)
+}
+
+// nullJSON stores a missing snapshot as NULL instead of an invalid empty document
+func nullJSON(doc []byte) any {
+	if len(doc) == 0 {
+		return nil
+	}
+	return string(doc)
+}
+
+func scanAuditEvent(row rowScanner) (*domain.AuditEvent, error) {
+	var e domain.AuditEvent
+	var domainID, workspaceID, userID, keyID sql.NullInt64
+	var before, after []byte
+	err := row.Scan(&e.ID, &e.Action, &e.LinkID, &e.Short, &domainID, &workspaceID, &userID, &keyID,
+		&e.IP, &e.RequestID, &before, &after, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	e.DomainID = domainID.Int64
+	e.WorkspaceID = workspaceID.Int64
+	e.ActorUserID = userID.Int64
+	e.ActorKeyID = keyID.Int64
+	e.Before = before
+	e.After = after
+	return &e, nil
+}
+
+// List returns the events matching filter, newest first
+func (r *PostgresAuditRepository) List(ctx context.Context, f domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	q := `SELECT ` + auditColumns + ` FROM audit_events
+		  WHERE ($1::BIGINT = 0 OR workspace_id = $1)
+		  AND ($2::TEXT = '' OR short = $2)
+		  AND ($3::BIGINT = 0 OR actor_user_id = $3)
+		  AND ($4::TEXT = '' OR action = $4)
+		  AND ($5::TIMESTAMP IS NULL OR created_at >= $5)
+		  AND ($6::TIMESTAMP IS NULL OR created_at < $6)
+		  AND (NOT $9::BOOLEAN OR domain_id IS NOT DISTINCT FROM $10::BIGINT)
+		  ORDER BY created_at DESC, id DESC
+		  LIMIT $7 OFFSET $8`
+
+	from := sql.NullTime{Time: f.From, Valid: !f.From.IsZero()}
+	to := sql.NullTime{Time: f.To, Valid: !f.To.IsZero()}
+	var domainID any
+	if f.DomainID != nil {
+		domainID = nullID(*f.DomainID)
+	}
+	rows, err := r.db.QueryContext(ctx, q, f.WorkspaceID, f.Short, f.ActorUserID, f.Action, from, to, f.Limit, f.Offset,
+		f.DomainID != nil, domainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.AuditEvent
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const domainColumns = `id, workspace_id, host, verification_token, verified_at, created_at`
+
+// PostgresDomainRepository keeps the custom domains of workspaces
+type PostgresDomainRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresDomainRepository(db *dbpg.DB) DomainRepository {
+	return &PostgresDomainRepository{db: db}
+}
+
+func scanDomain(row rowScanner) (*domain.CustomDomain, error) {
+	var d domain.CustomDomain
+	var verifiedAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WorkspaceID, &d.Host, &d.VerificationToken, &verifiedAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.VerifiedAt = verifiedAt.Time
+	return &d, nil
+}
+
+// isForeignKeyViolation reports whether err is a Postgres foreign_key_violation (23503)
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+func (r *PostgresDomainRepository) Create(ctx context.Context, d *domain.CustomDomain) error {
+	q := `INSERT INTO domains (workspace_id, host, verification_token, created_at)
+		  VALUES ($1, $2, $3, $4)
+		  RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, q, d.WorkspaceID, d.Host, d.VerificationToken, d.CreatedAt).Scan(&d.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrDomainTaken
+	}
+	return err
+}
+
+func (r *PostgresDomainRepository) FindByID(ctx context.Context, workspaceID, id int64) (*domain.CustomDomain, error) {
+	q := `SELECT ` + domainColumns + ` FROM domains WHERE id = $1 AND ($2::BIGINT = 0 OR workspace_id = $2)`
+
+	d, err := scanDomain(r.db.QueryRowContext(ctx, q, id, workspaceID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// FindByHost looks a normalized host up in workspaceID. Of several claims
+// the verified one is returned, then the oldest.
+func (r *PostgresDomainRepository) FindByHost(ctx context.Context, workspaceID int64, host string) (*domain.CustomDomain, error) {
+	q := `SELECT ` + domainColumns + ` FROM domains
+		  WHERE host = $1 AND ($2::BIGINT = 0 OR workspace_id = $2)
+		  ORDER BY verified_at IS NULL, id
+		  LIMIT 1`
+
+	d, err := scanDomain(r.db.QueryRowContext(ctx, q, host, workspaceID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *PostgresDomainRepository) List(ctx context.Context, workspaceID int64) ([]*domain.CustomDomain, error) {
+	q := `SELECT ` + domainColumns + ` FROM domains
+		  WHERE ($1::BIGINT = 0 OR workspace_id = $1)
+		  ORDER BY host`
+
+	rows, err := r.db.QueryContext(ctx, q, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []*domain.CustomDomain
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+// MarkVerified reports domain.ErrDomainTaken if another workspace verified the host first
+func (r *PostgresDomainRepository) MarkVerified(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE domains SET verified_at = $2 WHERE id = $1`, id, at)
+	if isUniqueViolation(err) {
+		return domain.ErrDomainTaken
+	}
+	return err
+}
+
+// Delete removes a domain without links, it reports false if the domain was
+// already gone and domain.ErrDomainInUse while links remain on it
+func (r *PostgresDomainRepository) Delete(ctx context.Context, workspaceID, id int64) (bool, error) {
+	q := `DELETE FROM domains WHERE id = $1 AND ($2::BIGINT = 0 OR workspace_id = $2)`
+
+	res, err := r.db.ExecContext(ctx, q, id, workspaceID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return false, domain.ErrDomainInUse
+		}
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
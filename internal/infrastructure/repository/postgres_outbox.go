@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// outboxColumns must stay in sync with scanOutboxEvent
+const outboxColumns = `id, type, link_id, short, workspace_id, payload, occurred_at`
+
+// insertOutboxEvent stores e in the outbox inside the transaction of the change it describes
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, e *domain.DomainEvent) error {
+	q := `INSERT INTO outbox_events (type, link_id, short, workspace_id, payload, occurred_at)
+		  VALUES ($1, $2, $3, $4, $5, $6)
+		  RETURNING id`
+
+	return tx.QueryRowContext(ctx, q, e.Type, e.LinkID, e.Short, nullID(e.WorkspaceID),
+		string(e.Payload), e.OccurredAt).Scan(&e.ID)
+}
+
+func scanOutboxEvent(row rowScanner) (*domain.DomainEvent, error) {
+	var e domain.DomainEvent
+	var workspaceID sql.NullInt64
+	var payload []byte
+	err := row.Scan(&e.ID, &e.Type, &e.LinkID, &e.Short, &workspaceID, &payload, &e.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+	e.WorkspaceID = workspaceID.Int64
+	e.Payload = payload
+	return &e, nil
+}
+
+type PostgresOutboxRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresOutboxRepository(db *dbpg.DB) OutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+// ClaimPending returns up to limit unpublished events in the order they were
+// written and hides them from other relays for lease
+func (r *PostgresOutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.DomainEvent, error) {
+	q := `UPDATE outbox_events SET available_at = now() + $2 * INTERVAL '1 millisecond'
+		  WHERE id IN (
+			  SELECT id FROM outbox_events
+			  WHERE published_at IS NULL AND available_at <= now()
+			  ORDER BY id
+			  LIMIT $1
+			  FOR UPDATE SKIP LOCKED
+		  )
+		  RETURNING ` + outboxColumns
+
+	rows, err := r.db.QueryContext(ctx, q, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.DomainEvent
+	for rows.Next() {
+		e, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(events, func(a, b *domain.DomainEvent) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		}
+		return 0
+	})
+	return events, nil
+}
+
+func (r *PostgresOutboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox_events SET published_at = now() WHERE id = ANY($1)`, pq.Array(ids))
+	return err
+}
+
+// DeletePublished drops events published before the given time and returns how many
+func (r *PostgresOutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM outbox_events WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
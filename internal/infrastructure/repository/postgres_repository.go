@@ -3,44 +3,220 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/wb-go/wbf/dbpg"
 	wbfretry "github.com/wb-go/wbf/retry"
 	"github.com/yokitheyo/URLShortener/internal/domain"
 	internalRetry "github.com/yokitheyo/URLShortener/internal/retry"
 )
 
+// urlColumns must stay in sync with scanURL
+const urlColumns = `id, short, original, created_at, expires_at, visits, sticky_mode,
+	forward_query, path_passthrough,
+	utm_source, utm_medium, utm_campaign, utm_term, utm_content, utm_mode,
+	redirect_code, risk_flag, warnings_shown, warnings_proceeded,
+	is_disabled, health_status, health_latency_ms, health_failures, health_checked_at, broken_since, fallback_url,
+	original_hash, is_custom, canonical, owner_id, workspace_id, signed, domain_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanURL(row rowScanner) (*domain.URL, error) {
+	u := &domain.URL{}
+	var checkedAt, brokenSince sql.NullTime
+	var ownerID, workspaceID, domainID sql.NullInt64
+	err := row.Scan(&u.ID, &u.Short, &u.Original, &u.CreatedAt, &u.ExpiresAt, &u.Visits, &u.StickyMode,
+		&u.ForwardQuery, &u.PathPassthrough,
+		&u.UTM.Source, &u.UTM.Medium, &u.UTM.Campaign, &u.UTM.Term, &u.UTM.Content, &u.UTMMode,
+		&u.RedirectCode, &u.RiskFlag, &u.WarningsShown, &u.WarningsProceeded,
+		&u.IsDisabled, &u.Health.Status, &u.Health.LatencyMs, &u.Health.Failures, &checkedAt, &brokenSince, &u.FallbackURL,
+		&u.OriginalHash, &u.IsCustom, &u.Canonical, &ownerID, &workspaceID, &u.Signed, &domainID)
+	if err != nil {
+		return nil, err
+	}
+	u.Health.CheckedAt = checkedAt.Time
+	u.Health.BrokenSince = brokenSince.Time
+	u.OwnerID = ownerID.Int64
+	u.WorkspaceID = workspaceID.Int64
+	u.DomainID = domainID.Int64
+	return u, nil
+}
+
+// nullID stores a missing owner, workspace or domain as NULL so the foreign key accepts it
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation (23505)
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func scanURLs(rows *sql.Rows) ([]*domain.URL, error) {
+	defer rows.Close()
+
+	var urls []*domain.URL
+	for rows.Next() {
+		u, err := scanURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, rows.Err()
+}
+
 type PostgresURLRepository struct {
 	db            *dbpg.DB
 	retryStrategy wbfretry.Strategy
+	outbox        bool
 }
 
-func NewPostgresURLRepository(db *dbpg.DB, strategy wbfretry.Strategy) URLRepository {
+// NewPostgresURLRepository stores links in db. With outbox set, link creation,
+// clicks and expiries also write their domain event to the outbox.
+func NewPostgresURLRepository(db *dbpg.DB, strategy wbfretry.Strategy, outbox bool) URLRepository {
 	if strategy.Attempts <= 0 {
 		strategy = internalRetry.DefaultStrategy
 	}
 	return &PostgresURLRepository{
 		db:            db,
 		retryStrategy: strategy,
+		outbox:        outbox,
+	}
+}
+
+// emit writes e to the outbox in tx when the outbox is enabled
+func (r *PostgresURLRepository) emit(ctx context.Context, tx *sql.Tx, e domain.DomainEvent) error {
+	if !r.outbox {
+		return nil
 	}
+	return insertOutboxEvent(ctx, tx, &e)
 }
 
-func (r *PostgresURLRepository) Create(ctx context.Context, u *domain.URL) error {
-	q := `INSERT INTO urls (short, original, created_at, expires_at)
-		  VALUES ($1, $2, $3, $4)
+// Create stores u with its variants and records event, which gets the new
+// link's id and code. A nil event is not recorded.
+func (r *PostgresURLRepository) Create(ctx context.Context, u *domain.URL, event *domain.AuditEvent) error {
+	if u.StickyMode == "" {
+		u.StickyMode = domain.StickyCookie
+	}
+	if u.UTMMode == "" {
+		u.UTMMode = domain.UTMMerge
+	}
+
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `INSERT INTO urls (short, original, created_at, expires_at, sticky_mode, forward_query, path_passthrough,
+		  utm_source, utm_medium, utm_campaign, utm_term, utm_content, utm_mode, redirect_code, risk_flag,
+		  fallback_url, original_hash, is_custom, canonical, owner_id, workspace_id, signed, domain_id)
+		  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
 		  RETURNING id, created_at`
 
-	row := r.db.QueryRowContext(ctx, q, u.Short, u.Original, u.CreatedAt, u.ExpiresAt)
-	return row.Scan(&u.ID, &u.CreatedAt)
+	row := tx.QueryRowContext(ctx, q, u.Short, u.Original, u.CreatedAt, u.ExpiresAt, u.StickyMode,
+		u.ForwardQuery, u.PathPassthrough,
+		u.UTM.Source, u.UTM.Medium, u.UTM.Campaign, u.UTM.Term, u.UTM.Content, u.UTMMode, u.RedirectCode, u.RiskFlag,
+		u.FallbackURL, u.OriginalHash, u.IsCustom, u.Canonical, nullID(u.OwnerID), nullID(u.WorkspaceID), u.Signed, nullID(u.DomainID))
+	if err := row.Scan(&u.ID, &u.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrShortConflict
+		}
+		return err
+	}
+
+	vq := `INSERT INTO url_variants (url_id, label, destination, weight)
+		   VALUES ($1, $2, $3, $4)
+		   RETURNING id`
+
+	for i := range u.Variants {
+		v := &u.Variants[i]
+		v.URLID = u.ID
+		if err := tx.QueryRowContext(ctx, vq, v.URLID, v.Label, v.Destination, v.Weight).Scan(&v.ID); err != nil {
+			return err
+		}
+	}
+
+	if event != nil {
+		event.LinkID = u.ID
+		event.Short = u.Short
+		if err := insertAuditEvent(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+	if err := r.emit(ctx, tx, domain.NewLinkCreated(u)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *PostgresURLRepository) FindByShort(ctx context.Context, short string) (*domain.URL, error) {
-	q := `SELECT id, short, original, created_at, expires_at, visits FROM urls WHERE short = $1`
+// Update saves the editable fields of u and records event, it reports
+// false if the link no longer exists
+func (r *PostgresURLRepository) Update(ctx context.Context, u *domain.URL, event *domain.AuditEvent) (bool, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
 
-	row := r.db.QueryRowContext(ctx, q, short)
-	u := &domain.URL{}
-	err := row.Scan(&u.ID, &u.Short, &u.Original, &u.CreatedAt, &u.ExpiresAt, &u.Visits)
+	// a link whose expiry moves into the future will be announced again when it expires
+	q := `UPDATE urls SET original = $2, canonical = $3, original_hash = $4, expires_at = $5,
+		  fallback_url = $6, redirect_code = $7, risk_flag = $8, is_disabled = $9,
+		  expiry_notified = expiry_notified AND $5 <= now()
+		  WHERE id = $1`
+	res, err := tx.ExecContext(ctx, q, u.ID, u.Original, u.Canonical, u.OriginalHash, u.ExpiresAt,
+		u.FallbackURL, u.RedirectCode, u.RiskFlag, u.IsDisabled)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Delete removes a link with its variants and clicks and records event,
+// it reports false if the link was already gone
+func (r *PostgresURLRepository) Delete(ctx context.Context, id int64, event *domain.AuditEvent) (bool, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM urls WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// FindByShort looks the code up on domainID in workspaceID. Codes are unique
+// per domain across workspaces, so domain.AllWorkspaces finds any link.
+func (r *PostgresURLRepository) FindByShort(ctx context.Context, workspaceID, domainID int64, short string) (*domain.URL, error) {
+	q := `SELECT ` + urlColumns + ` FROM urls WHERE short = $1 AND ($2::BIGINT = 0 OR workspace_id = $2)
+		  AND (($3::BIGINT = 0 AND domain_id IS NULL) OR domain_id = $3)`
+
+	u, err := scanURL(r.db.QueryRowContext(ctx, q, short, workspaceID, domainID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -51,19 +227,111 @@ func (r *PostgresURLRepository) FindByShort(ctx context.Context, short string) (
 	return u, nil
 }
 
+// FindReusable returns an active generated link to the same destination that
+// has no per-link options, so handing it out again changes nothing for the caller.
+// Only links of the same workspace and domain are considered. Links outside
+// any workspace (workspaceID 0) must also belong to ownerID: only the owner
+// may edit them, nobody else's published code may be retargeted under them.
+func (r *PostgresURLRepository) FindReusable(ctx context.Context, workspaceID, ownerID, domainID int64, hash string, minExpiresAt time.Time) (*domain.URL, error) {
+	q := `SELECT ` + urlColumns + ` FROM urls u
+		  WHERE original_hash = $1 AND COALESCE(workspace_id, 0) = $3 AND NOT is_custom AND NOT is_disabled AND NOT signed
+		  AND ($3::BIGINT <> 0 OR COALESCE(owner_id, 0) = $5)
+		  AND COALESCE(domain_id, 0) = $4
+		  AND expires_at > now() AND expires_at >= $2
+		  AND NOT forward_query AND NOT path_passthrough AND redirect_code = 0 AND fallback_url = ''
+		  AND utm_source = '' AND utm_medium = '' AND utm_campaign = '' AND utm_term = '' AND utm_content = ''
+		  AND NOT EXISTS (SELECT 1 FROM url_variants v WHERE v.url_id = u.id)
+		  ORDER BY expires_at DESC
+		  LIMIT 1`
+
+	u, err := scanURL(r.db.QueryRowContext(ctx, q, hash, minExpiresAt, workspaceID, domainID, ownerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
 func (r *PostgresURLRepository) IncrementVisits(ctx context.Context, id int64) error {
 	q := `UPDATE urls SET visits = visits + 1 WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, q, id)
 	return err
 }
 
-func (r *PostgresURLRepository) AggregateByDay(ctx context.Context, short string, from, to time.Time) (map[string]int64, error) {
+func (r *PostgresURLRepository) IncrementWarningsShown(ctx context.Context, id int64) error {
+	q := `UPDATE urls SET warnings_shown = warnings_shown + 1 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, q, id)
+	return err
+}
+
+func (r *PostgresURLRepository) IncrementWarningsProceeded(ctx context.Context, id int64) error {
+	q := `UPDATE urls SET warnings_proceeded = warnings_proceeded + 1 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, q, id)
+	return err
+}
+
+// ListForHealthCheck takes the due links of every host in turn, least
+// recently checked first: the checker probes a host once per round, so a
+// host with many links must not fill the batch and starve the others.
+func (r *PostgresURLRepository) ListForHealthCheck(ctx context.Context, workspaceID int64, checkedBefore time.Time, limit int) ([]*domain.URL, error) {
+	q := `SELECT ` + urlColumns + ` FROM (
+			SELECT urls.*, ROW_NUMBER() OVER (
+				PARTITION BY lower(substring(original FROM '^[^:]+://([^/?#:]+)'))
+				ORDER BY health_checked_at NULLS FIRST, id
+			) AS host_rank
+			FROM urls
+			WHERE NOT is_disabled AND expires_at > now()
+			AND (health_checked_at IS NULL OR health_checked_at < $1)
+			AND ($3::BIGINT = 0 OR workspace_id = $3)
+		  ) due
+		  ORDER BY host_rank, health_checked_at NULLS FIRST, id
+		  LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, q, checkedBefore, limit, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return scanURLs(rows)
+}
+
+func (r *PostgresURLRepository) UpdateHealth(ctx context.Context, id int64, status int, latencyMs int64, healthy bool, checkedAt time.Time) error {
+	q := `UPDATE urls SET
+		  health_status = $2,
+		  health_latency_ms = $3,
+		  health_checked_at = $5,
+		  health_failures = CASE WHEN $4 THEN 0 ELSE health_failures + 1 END,
+		  broken_since = CASE WHEN $4 THEN NULL ELSE COALESCE(broken_since, $5) END
+		  WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, q, id, status, latencyMs, healthy, checkedAt)
+	return err
+}
+
+// ListUnhealthy returns failing links of workspaceID
+func (r *PostgresURLRepository) ListUnhealthy(ctx context.Context, workspaceID int64, minFailures, limit int) ([]*domain.URL, error) {
+	q := `SELECT ` + urlColumns + ` FROM urls
+		  WHERE health_failures >= $1 AND NOT is_disabled AND expires_at > now()
+		  AND ($3::BIGINT = 0 OR workspace_id = $3)
+		  ORDER BY broken_since, id
+		  LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, q, minFailures, limit, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return scanURLs(rows)
+}
+
+func (r *PostgresURLRepository) AggregateByDay(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error) {
 	q := `SELECT DATE(occurred_at)::TEXT as date, COUNT(*) as count
-		  FROM clicks WHERE short = $1 AND occurred_at BETWEEN $2 AND $3
+		  FROM clicks
+		  WHERE url_id = $1 AND occurred_at BETWEEN $2 AND $3
 		  GROUP BY DATE(occurred_at)
 		  ORDER BY date`
 
-	rows, err := r.db.QueryContext(ctx, q, short, from, to)
+	rows, err := r.db.QueryContext(ctx, q, urlID, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -82,12 +350,13 @@ func (r *PostgresURLRepository) AggregateByDay(ctx context.Context, short string
 	return result, rows.Err()
 }
 
-func (r *PostgresURLRepository) GetDeviceStats(ctx context.Context, short string, from, to time.Time) (map[string]int64, error) {
-	q := `SELECT device, COUNT(*) as count FROM clicks
-		  WHERE short = $1 AND occurred_at BETWEEN $2 AND $3
+func (r *PostgresURLRepository) GetDeviceStats(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error) {
+	q := `SELECT device, COUNT(*) as count
+		  FROM clicks
+		  WHERE url_id = $1 AND occurred_at BETWEEN $2 AND $3
 		  GROUP BY device`
 
-	rows, err := r.db.QueryContext(ctx, q, short, from, to)
+	rows, err := r.db.QueryContext(ctx, q, urlID, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -107,19 +376,114 @@ func (r *PostgresURLRepository) GetDeviceStats(ctx context.Context, short string
 }
 
 func (r *PostgresURLRepository) SaveClick(ctx context.Context, c *domain.Click) error {
-	q := `INSERT INTO clicks (url_id, short, occurred_at, user_agent, ip, referrer, device)
-		  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	_, err := r.db.ExecContext(ctx, q, c.URLID, c.Short, c.OccurredAt, c.UserAgent, c.IP, c.Referrer, c.Device)
-	return err
+	q := `INSERT INTO clicks (url_id, short, occurred_at, user_agent, ip, referrer, device, variant_id)
+		  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		  RETURNING id`
+
+	variantID := sql.NullInt64{Int64: c.VariantID, Valid: c.VariantID > 0}
+	err = tx.QueryRowContext(ctx, q, c.URLID, c.Short, c.OccurredAt, c.UserAgent, c.IP, c.Referrer, c.Device, variantID).
+		Scan(&c.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.emit(ctx, tx, domain.NewLinkClicked(c)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *PostgresURLRepository) GetRecentClicks(ctx context.Context, short string, limit int) ([]*domain.Click, error) {
-	q := `SELECT id, url_id, short, occurred_at, user_agent, ip, referrer, device
-		  FROM clicks WHERE short = $1
-		  ORDER BY occurred_at DESC LIMIT $2`
+func (r *PostgresURLRepository) GetVariants(ctx context.Context, urlID int64) ([]domain.Variant, error) {
+	q := `SELECT id, url_id, label, destination, weight
+		  FROM url_variants WHERE url_id = $1
+		  ORDER BY id`
 
-	rows, err := r.db.QueryContext(ctx, q, short, limit)
+	rows, err := r.db.QueryContext(ctx, q, urlID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []domain.Variant
+	for rows.Next() {
+		var v domain.Variant
+		if err := rows.Scan(&v.ID, &v.URLID, &v.Label, &v.Destination, &v.Weight); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+
+	return variants, rows.Err()
+}
+
+func (r *PostgresURLRepository) GetVariantStats(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error) {
+	q := `SELECT v.label, COUNT(*) as count FROM clicks c
+		  JOIN url_variants v ON v.id = c.variant_id
+		  WHERE c.url_id = $1 AND c.occurred_at BETWEEN $2 AND $3
+		  GROUP BY v.label`
+
+	rows, err := r.db.QueryContext(ctx, q, urlID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int64)
+	for rows.Next() {
+		var label string
+		var count int64
+		if err := rows.Scan(&label, &count); err != nil {
+			return nil, err
+		}
+		result[label] = count
+	}
+
+	return result, rows.Err()
+}
+
+// GetCampaignStats groups the links of workspaceID by campaign
+func (r *PostgresURLRepository) GetCampaignStats(ctx context.Context, workspaceID int64) ([]domain.CampaignStats, error) {
+	q := `SELECT utm_campaign, COUNT(*) as links, COALESCE(SUM(visits), 0) as visits,
+		  string_agg(short, ',' ORDER BY short) as shorts
+		  FROM urls WHERE utm_campaign <> '' AND ($1::BIGINT = 0 OR workspace_id = $1)
+		  GROUP BY utm_campaign
+		  ORDER BY visits DESC, utm_campaign`
+
+	rows, err := r.db.QueryContext(ctx, q, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.CampaignStats
+	for rows.Next() {
+		var st domain.CampaignStats
+		var shorts string
+		if err := rows.Scan(&st.Campaign, &st.Links, &st.Visits, &shorts); err != nil {
+			return nil, err
+		}
+		st.Shorts = strings.Split(shorts, ",")
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+func (r *PostgresURLRepository) GetRecentClicks(ctx context.Context, urlID int64, limit int) ([]*domain.Click, error) {
+	q := `SELECT c.id, c.url_id, c.short, c.occurred_at, c.user_agent, c.ip, c.referrer, c.device,
+		  COALESCE(c.variant_id, 0), COALESCE(v.label, '')
+		  FROM clicks c
+		  LEFT JOIN url_variants v ON v.id = c.variant_id
+		  WHERE c.url_id = $1
+		  ORDER BY c.occurred_at DESC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, q, urlID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +492,7 @@ func (r *PostgresURLRepository) GetRecentClicks(ctx context.Context, short strin
 	var clicks []*domain.Click
 	for rows.Next() {
 		click := &domain.Click{}
-		err := rows.Scan(&click.ID, &click.URLID, &click.Short, &click.OccurredAt, &click.UserAgent, &click.IP, &click.Referrer, &click.Device)
+		err := rows.Scan(&click.ID, &click.URLID, &click.Short, &click.OccurredAt, &click.UserAgent, &click.IP, &click.Referrer, &click.Device, &click.VariantID, &click.Variant)
 		if err != nil {
 			return nil, err
 		}
@@ -137,3 +501,55 @@ func (r *PostgresURLRepository) GetRecentClicks(ctx context.Context, short strin
 
 	return clicks, rows.Err()
 }
+
+// ListLinks returns the links of workspaceID, newest first. A non-zero ownerID
+// keeps only the links that user created.
+func (r *PostgresURLRepository) ListLinks(ctx context.Context, workspaceID, ownerID int64, limit, offset int) ([]*domain.URL, error) {
+	q := `SELECT ` + urlColumns + ` FROM urls
+		  WHERE ($1::BIGINT = 0 OR workspace_id = $1) AND ($2::BIGINT = 0 OR owner_id = $2)
+		  ORDER BY created_at DESC, id DESC
+		  LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, q, workspaceID, ownerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanURLs(rows)
+}
+
+// ClaimExpired marks up to limit links that expired since the last call as
+// announced, writes their LinkExpired events and returns them. Each link is
+// returned once.
+func (r *PostgresURLRepository) ClaimExpired(ctx context.Context, limit int) ([]*domain.URL, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	q := `UPDATE urls SET expiry_notified = true
+		  WHERE id IN (
+			  SELECT id FROM urls
+			  WHERE NOT expiry_notified AND expires_at <= now()
+			  ORDER BY expires_at
+			  LIMIT $1
+			  FOR UPDATE SKIP LOCKED
+		  )
+		  RETURNING ` + urlColumns
+
+	rows, err := tx.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	urls, err := scanURLs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range urls {
+		if err := r.emit(ctx, tx, domain.NewLinkExpired(u)); err != nil {
+			return nil, err
+		}
+	}
+	return urls, tx.Commit()
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// PostgresReservedWordRepository keeps admin-managed reserved words in reserved_words.
+// Words are stored lowercased by the caller.
+type PostgresReservedWordRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresReservedWordRepository(db *dbpg.DB) ReservedWordRepository {
+	return &PostgresReservedWordRepository{db: db}
+}
+
+func (r *PostgresReservedWordRepository) List(ctx context.Context) ([]domain.ReservedWord, error) {
+	q := `SELECT word, created_at FROM reserved_words ORDER BY word`
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var words []domain.ReservedWord
+	for rows.Next() {
+		w := domain.ReservedWord{Source: domain.ReservedByAdmin}
+		if err := rows.Scan(&w.Word, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+	return words, rows.Err()
+}
+
+func (r *PostgresReservedWordRepository) Contains(ctx context.Context, word string) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM reserved_words WHERE word = $1)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, q, word).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *PostgresReservedWordRepository) Add(ctx context.Context, word string) (bool, error) {
+	q := `INSERT INTO reserved_words (word) VALUES ($1) ON CONFLICT (word) DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, q, word)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *PostgresReservedWordRepository) Remove(ctx context.Context, word string) (bool, error) {
+	q := `DELETE FROM reserved_words WHERE word = $1`
+
+	res, err := r.db.ExecContext(ctx, q, word)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const userColumns = `id, email, password_hash, is_admin, created_at`
+
+// PostgresUserRepository keeps accounts in users and web UI logins in sessions
+type PostgresUserRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresUserRepository(db *dbpg.DB) UserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var u domain.User
+	if err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.IsAdmin, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Create returns domain.ErrEmailTaken if the email is already registered
+func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
+	q := `INSERT INTO users (email, password_hash, is_admin, created_at)
+		VALUES ($1, $2, $3, $4) RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, q, user.Email, user.PasswordHash, user.IsAdmin, user.CreatedAt).Scan(&user.ID)
+	if isUniqueViolation(err) {
+		return domain.ErrEmailTaken
+	}
+	return err
+}
+
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return r.findOne(ctx, q, email)
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id int64) (*domain.User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return r.findOne(ctx, q, id)
+}
+
+func (r *PostgresUserRepository) findOne(ctx context.Context, q string, arg any) (*domain.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx, q, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+func (r *PostgresUserRepository) CreateSession(ctx context.Context, s *domain.Session) error {
+	q := `INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES ($1, $2, $3, $4)`
+
+	_, err := r.db.ExecContext(ctx, q, s.TokenHash, s.UserID, s.CreatedAt, s.ExpiresAt)
+	return err
+}
+
+// FindSession returns the session unless it has expired by now
+func (r *PostgresUserRepository) FindSession(ctx context.Context, tokenHash string, now time.Time) (*domain.Session, error) {
+	q := `SELECT token_hash, user_id, created_at, expires_at FROM sessions
+		  WHERE token_hash = $1 AND expires_at > $2`
+
+	var s domain.Session
+	err := r.db.QueryRowContext(ctx, q, tokenHash, now).Scan(&s.TokenHash, &s.UserID, &s.CreatedAt, &s.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PostgresUserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	q := `DELETE FROM sessions WHERE token_hash = $1`
+
+	_, err := r.db.ExecContext(ctx, q, tokenHash)
+	return err
+}
+
+func (r *PostgresUserRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	q := `DELETE FROM sessions WHERE expires_at <= $1`
+
+	res, err := r.db.ExecContext(ctx, q, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
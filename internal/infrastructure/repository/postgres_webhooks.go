@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const webhookColumns = `w.id, w.workspace_id, w.link_id, COALESCE(u.short, ''), w.url, w.secret, w.events,
+	w.created_by, w.created_at`
+
+const deliveryColumns = `id, webhook_id, event, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, delivered_at`
+
+// PostgresWebhookRepository keeps webhook subscriptions and their delivery outbox
+type PostgresWebhookRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresWebhookRepository(db *dbpg.DB) WebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	var w domain.Webhook
+	var linkID, createdBy sql.NullInt64
+	err := row.Scan(&w.ID, &w.WorkspaceID, &linkID, &w.Short, &w.URL, &w.Secret, pq.Array(&w.Events),
+		&createdBy, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	w.LinkID = linkID.Int64
+	w.CreatedBy = createdBy.Int64
+	return &w, nil
+}
+
+func scanDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var payload []byte
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatus, &d.LastError, &d.CreatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	d.DeliveredAt = deliveredAt.Time
+	return &d, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) Create(ctx context.Context, w *domain.Webhook) error {
+	q := `INSERT INTO webhooks (workspace_id, link_id, url, secret, events, created_by, created_at)
+		  VALUES ($1, $2, $3, $4, $5, $6, $7)
+		  RETURNING id`
+
+	return r.db.QueryRowContext(ctx, q, w.WorkspaceID, nullID(w.LinkID), w.URL, w.Secret, pq.Array(w.Events),
+		nullID(w.CreatedBy), w.CreatedAt).Scan(&w.ID)
+}
+
+func (r *PostgresWebhookRepository) FindByID(ctx context.Context, workspaceID, id int64) (*domain.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks w LEFT JOIN urls u ON u.id = w.link_id
+		  WHERE w.id = $1 AND ($2::BIGINT = 0 OR w.workspace_id = $2)`
+
+	w, err := scanWebhook(r.db.QueryRowContext(ctx, q, id, workspaceID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *PostgresWebhookRepository) List(ctx context.Context, workspaceID int64) ([]*domain.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks w LEFT JOIN urls u ON u.id = w.link_id
+		  WHERE ($1::BIGINT = 0 OR w.workspace_id = $1)
+		  ORDER BY w.id`
+
+	rows, err := r.db.QueryContext(ctx, q, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []*domain.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) Delete(ctx context.Context, workspaceID, id int64) (bool, error) {
+	q := `DELETE FROM webhooks WHERE id = $1 AND ($2::BIGINT = 0 OR workspace_id = $2)`
+	res, err := r.db.ExecContext(ctx, q, id, workspaceID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Enqueue fans event out to the subscriptions of the workspace and of the link
+func (r *PostgresWebhookRepository) Enqueue(ctx context.Context, workspaceID, linkID int64, event string, payload json.RawMessage) (int, error) {
+	q := `INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		  SELECT id, $3, $4 FROM webhooks
+		  WHERE workspace_id = $1 AND (link_id IS NULL OR link_id = $2) AND $3 = ANY(events)`
+
+	res, err := r.db.ExecContext(ctx, q, workspaceID, linkID, event, string(payload))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// ClaimDue locks due deliveries with SKIP LOCKED, so concurrent dispatchers
+// never claim the same one, and pushes them lease into the future. A
+// dispatcher that dies mid-send leaves them to be retried once the lease ends.
+func (r *PostgresWebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	q := `UPDATE webhook_deliveries SET next_attempt_at = now() + $2 * INTERVAL '1 millisecond'
+		  WHERE id IN (
+			  SELECT id FROM webhook_deliveries
+			  WHERE status = 'pending' AND next_attempt_at <= now()
+			  ORDER BY next_attempt_at
+			  LIMIT $1
+			  FOR UPDATE SKIP LOCKED
+		  )
+		  RETURNING ` + deliveryColumns
+
+	rows, err := r.db.QueryContext(ctx, q, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
+
+func (r *PostgresWebhookRepository) MarkDelivered(ctx context.Context, id int64, status int) error {
+	q := `UPDATE webhook_deliveries
+		  SET status = 'delivered', attempts = attempts + 1, last_status_code = $2, last_error = '', delivered_at = now()
+		  WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, q, id, status)
+	return err
+}
+
+// MarkFailed records a failed attempt and schedules the next one, or
+// dead-letters the delivery when dead is set
+func (r *PostgresWebhookRepository) MarkFailed(ctx context.Context, id int64, status int, reason string, nextAttemptAt time.Time, dead bool) error {
+	q := `UPDATE webhook_deliveries
+		  SET status = CASE WHEN $5 THEN 'dead' ELSE 'pending' END,
+		  attempts = attempts + 1, last_status_code = $2, last_error = $3, next_attempt_at = $4
+		  WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, q, id, status, reason, nextAttemptAt, dead)
+	return err
+}
+
+// ListDeliveries returns the deliveries of a webhook, newest first. An empty
+// status returns all of them.
+func (r *PostgresWebhookRepository) ListDeliveries(ctx context.Context, webhookID int64, status string, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	q := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		  WHERE webhook_id = $1 AND ($2::TEXT = '' OR status = $2)
+		  ORDER BY created_at DESC, id DESC
+		  LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, q, webhookID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const memberColumns = `m.workspace_id, m.user_id, u.email, m.role, m.created_at`
+
+// PostgresWorkspaceRepository keeps workspaces and their members' roles
+type PostgresWorkspaceRepository struct {
+	db *dbpg.DB
+}
+
+func NewPostgresWorkspaceRepository(db *dbpg.DB) WorkspaceRepository {
+	return &PostgresWorkspaceRepository{db: db}
+}
+
+func scanMember(row rowScanner) (*domain.Member, error) {
+	var m domain.Member
+	if err := row.Scan(&m.WorkspaceID, &m.UserID, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Create stores ws and makes ownerID its owner in one transaction
+func (r *PostgresWorkspaceRepository) Create(ctx context.Context, ws *domain.Workspace, ownerID int64) error {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `INSERT INTO workspaces (name, created_at) VALUES ($1, $2) RETURNING id`
+	if err := tx.QueryRowContext(ctx, q, ws.Name, ws.CreatedAt).Scan(&ws.ID); err != nil {
+		return err
+	}
+
+	mq := `INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.ExecContext(ctx, mq, ws.ID, ownerID, domain.RoleOwner, ws.CreatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresWorkspaceRepository) FindByID(ctx context.Context, id int64) (*domain.Workspace, error) {
+	q := `SELECT id, name, created_at FROM workspaces WHERE id = $1`
+
+	var ws domain.Workspace
+	if err := r.db.QueryRowContext(ctx, q, id).Scan(&ws.ID, &ws.Name, &ws.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// ListForUser returns the workspaces userID belongs to, oldest membership first
+func (r *PostgresWorkspaceRepository) ListForUser(ctx context.Context, userID int64) ([]domain.Membership, error) {
+	q := `SELECT w.id, w.name, w.created_at, m.role
+		  FROM workspace_members m JOIN workspaces w ON w.id = m.workspace_id
+		  WHERE m.user_id = $1
+		  ORDER BY m.created_at, w.id`
+
+	rows, err := r.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memberships []domain.Membership
+	for rows.Next() {
+		var m domain.Membership
+		if err := rows.Scan(&m.Workspace.ID, &m.Workspace.Name, &m.Workspace.CreatedAt, &m.Role); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, m)
+	}
+	return memberships, rows.Err()
+}
+
+func (r *PostgresWorkspaceRepository) FindMember(ctx context.Context, workspaceID, userID int64) (*domain.Member, error) {
+	q := `SELECT ` + memberColumns + ` FROM workspace_members m JOIN users u ON u.id = m.user_id
+		  WHERE m.workspace_id = $1 AND m.user_id = $2`
+	return r.findMember(ctx, q, workspaceID, userID)
+}
+
+// FirstMembership returns the oldest membership of userID, the workspace
+// requests work in when they do not pick one
+func (r *PostgresWorkspaceRepository) FirstMembership(ctx context.Context, userID int64) (*domain.Member, error) {
+	q := `SELECT ` + memberColumns + ` FROM workspace_members m JOIN users u ON u.id = m.user_id
+		  WHERE m.user_id = $1
+		  ORDER BY m.created_at, m.workspace_id
+		  LIMIT 1`
+	return r.findMember(ctx, q, userID)
+}
+
+func (r *PostgresWorkspaceRepository) findMember(ctx context.Context, q string, args ...any) (*domain.Member, error) {
+	m, err := scanMember(r.db.QueryRowContext(ctx, q, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *PostgresWorkspaceRepository) ListMembers(ctx context.Context, workspaceID int64) ([]domain.Member, error) {
+	q := `SELECT ` + memberColumns + ` FROM workspace_members m JOIN users u ON u.id = m.user_id
+		  WHERE m.workspace_id = $1
+		  ORDER BY m.created_at, u.email`
+
+	rows, err := r.db.QueryContext(ctx, q, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []domain.Member
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *m)
+	}
+	return members, rows.Err()
+}
+
+// SetMember adds userID to the workspace or changes the role it has there
+func (r *PostgresWorkspaceRepository) SetMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	q := `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)
+		  ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+
+	_, err := r.db.ExecContext(ctx, q, workspaceID, userID, role)
+	return err
+}
+
+func (r *PostgresWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID int64) (bool, error) {
+	q := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	res, err := r.db.ExecContext(ctx, q, workspaceID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *PostgresWorkspaceRepository) CountOwners(ctx context.Context, workspaceID int64) (int, error) {
+	q := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2`
+
+	var n int
+	err := r.db.QueryRowContext(ctx, q, workspaceID, domain.RoleOwner).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type ReservedWordRepository interface {
+	List(ctx context.Context) ([]domain.ReservedWord, error)
+	Contains(ctx context.Context, word string) (bool, error)
+	Add(ctx context.Context, word string) (bool, error)
+	Remove(ctx context.Context, word string) (bool, error)
+}
@@ -8,11 +8,24 @@ import (
 )
 
 type URLRepository interface {
-	Create(ctx context.Context, u *domain.URL) error
-	FindByShort(ctx context.Context, short string) (*domain.URL, error)
+	Create(ctx context.Context, u *domain.URL, event *domain.AuditEvent) error
+	FindByShort(ctx context.Context, workspaceID, domainID int64, short string) (*domain.URL, error)
 	IncrementVisits(ctx context.Context, id int64) error
-	AggregateByDay(ctx context.Context, short string, from, to time.Time) (map[string]int64, error)
-	GetDeviceStats(ctx context.Context, short string, from, to time.Time) (map[string]int64, error)
+	AggregateByDay(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error)
+	GetDeviceStats(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error)
 	SaveClick(ctx context.Context, c *domain.Click) error
-	GetRecentClicks(ctx context.Context, short string, limit int) ([]*domain.Click, error)
+	GetRecentClicks(ctx context.Context, urlID int64, limit int) ([]*domain.Click, error)
+	GetVariants(ctx context.Context, urlID int64) ([]domain.Variant, error)
+	GetVariantStats(ctx context.Context, urlID int64, from, to time.Time) (map[string]int64, error)
+	GetCampaignStats(ctx context.Context, workspaceID int64) ([]domain.CampaignStats, error)
+	Update(ctx context.Context, u *domain.URL, event *domain.AuditEvent) (bool, error)
+	Delete(ctx context.Context, id int64, event *domain.AuditEvent) (bool, error)
+	IncrementWarningsShown(ctx context.Context, id int64) error
+	IncrementWarningsProceeded(ctx context.Context, id int64) error
+	ListForHealthCheck(ctx context.Context, workspaceID int64, checkedBefore time.Time, limit int) ([]*domain.URL, error)
+	UpdateHealth(ctx context.Context, id int64, status int, latencyMs int64, healthy bool, checkedAt time.Time) error
+	ListUnhealthy(ctx context.Context, workspaceID int64, minFailures, limit int) ([]*domain.URL, error)
+	FindReusable(ctx context.Context, workspaceID, ownerID, domainID int64, hash string, minExpiresAt time.Time) (*domain.URL, error)
+	ListLinks(ctx context.Context, workspaceID, ownerID int64, limit, offset int) ([]*domain.URL, error)
+	ClaimExpired(ctx context.Context, limit int) ([]*domain.URL, error)
 }
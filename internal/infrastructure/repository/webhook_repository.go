@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *domain.Webhook) error
+	FindByID(ctx context.Context, workspaceID, id int64) (*domain.Webhook, error)
+	List(ctx context.Context, workspaceID int64) ([]*domain.Webhook, error)
+	Delete(ctx context.Context, workspaceID, id int64) (bool, error)
+	Enqueue(ctx context.Context, workspaceID, linkID int64, event string, payload json.RawMessage) (int, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, status int) error
+	MarkFailed(ctx context.Context, id int64, status int, reason string, nextAttemptAt time.Time, dead bool) error
+	ListDeliveries(ctx context.Context, webhookID int64, status string, limit, offset int) ([]*domain.WebhookDelivery, error)
+}
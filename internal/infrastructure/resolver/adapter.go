@@ -0,0 +1,8 @@
+package resolver
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// HTTPResolver implements ports.RedirectResolver interface
+var _ ports.RedirectResolver = (*HTTPResolver)(nil)
@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultTimeout = 5 * time.Second
+	userAgent      = "URLShortener-LinkResolver/1.0"
+	maxDrainBytes  = 64 << 10
+)
+
+// HTTPResolver expands short links one hop at a time. It never follows redirects
+// itself so the caller can vet every hop before requesting it.
+type HTTPResolver struct {
+	client *http.Client
+}
+
+// NewHTTPResolver wraps client (a default one if nil) and disables automatic redirects
+func NewHTTPResolver(client *http.Client, timeout time.Duration) Resolver {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	var c http.Client
+	if client != nil {
+		c = *client
+	}
+	if c.Timeout == 0 {
+		c.Timeout = timeout
+	}
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &HTTPResolver{client: &c}
+}
+
+func (r *HTTPResolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	next, status, err := r.hop(ctx, http.MethodHead, rawURL)
+	if err != nil {
+		return "", err
+	}
+	// some shorteners refuse HEAD
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		next, _, err = r.hop(ctx, http.MethodGet, rawURL)
+		if err != nil {
+			return "", err
+		}
+	}
+	return next, nil
+}
+
+func (r *HTTPResolver) hop(ctx context.Context, method, rawURL string) (string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("request %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+
+	if resp.StatusCode < 300 || resp.StatusCode > 399 {
+		return "", resp.StatusCode, nil
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", resp.StatusCode, nil
+	}
+
+	next, err := resp.Request.URL.Parse(location)
+	if err != nil {
+		return "", resp.StatusCode, fmt.Errorf("invalid Location %q: %w", location, err)
+	}
+	return next.String(), resp.StatusCode, nil
+}
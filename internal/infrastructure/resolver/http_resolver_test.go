@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveReturnsAbsoluteLocation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abc":
+			http.Redirect(w, r, "/next", http.StatusMovedPermanently)
+		case "/next":
+			t.Errorf("redirect was followed to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	next, err := NewHTTPResolver(srv.Client(), 0).Resolve(context.Background(), srv.URL+"/abc")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if want := srv.URL + "/next"; next != want {
+		t.Errorf("Resolve = %q, want %q", next, want)
+	}
+}
+
+func TestResolveFallsBackToGet(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "https://example.com/final", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	next, err := NewHTTPResolver(srv.Client(), 0).Resolve(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if next != "https://example.com/final" {
+		t.Errorf("Resolve = %q, want https://example.com/final", next)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Errorf("methods = %v, want [HEAD GET]", methods)
+	}
+}
+
+func TestResolveWithoutRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	next, err := NewHTTPResolver(srv.Client(), 0).Resolve(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if next != "" {
+		t.Errorf("Resolve = %q, want empty", next)
+	}
+}
@@ -0,0 +1,7 @@
+package resolver
+
+import "context"
+
+type Resolver interface {
+	Resolve(ctx context.Context, rawURL string) (string, error)
+}
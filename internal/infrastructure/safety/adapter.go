@@ -0,0 +1,8 @@
+package safety
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// FileBlocklist implements ports.Blocklist interface
+var _ ports.Blocklist = (*FileBlocklist)(nil)
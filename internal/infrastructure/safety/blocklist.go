@@ -0,0 +1,8 @@
+package safety
+
+import "context"
+
+type Blocklist interface {
+	Contains(host string) bool
+	Watch(ctx context.Context)
+}
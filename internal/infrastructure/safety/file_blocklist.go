@@ -0,0 +1,133 @@
+package safety
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"golang.org/x/net/idna"
+)
+
+const defaultReloadInterval = time.Minute
+
+// FileBlocklist keeps a set of blocked domains loaded from a local file and
+// reloads it whenever the file changes. The file holds one domain per line,
+// hosts-file lines ("0.0.0.0 evil.example") and # comments are accepted too.
+type FileBlocklist struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.RWMutex
+	domains map[string]struct{}
+	modTime time.Time
+}
+
+func NewFileBlocklist(path string, interval time.Duration) (Blocklist, error) {
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	b := &FileBlocklist{
+		path:     path,
+		interval: interval,
+		domains:  make(map[string]struct{}),
+	}
+	if err := b.reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Contains reports whether host or any of its parent domains is blocked
+func (b *FileBlocklist) Contains(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for host != "" {
+		if _, ok := b.domains[host]; ok {
+			return true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return false
+}
+
+// Watch polls the file until ctx is done and reloads it when its mtime changes
+func (b *FileBlocklist) Watch(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.reload(); err != nil {
+				zlog.Logger.Warn().Err(err).Str("path", b.path).Msg("failed to reload blocklist, keeping previous version")
+			}
+		}
+	}
+}
+
+func (b *FileBlocklist) reload() error {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return fmt.Errorf("stat blocklist: %w", err)
+	}
+
+	b.mu.RLock()
+	unchanged := info.ModTime().Equal(b.modTime)
+	b.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	f, err := os.Open(b.path)
+	if err != nil {
+		return fmt.Errorf("open blocklist: %w", err)
+	}
+	defer f.Close()
+
+	domains := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		domain := fields[len(fields)-1]
+		domain = strings.Trim(strings.ToLower(domain), ".")
+		// internationalized entries are matched in their punycode form
+		if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+			domain = ascii
+		}
+		if domain != "" && domain != "localhost" {
+			domains[domain] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read blocklist: %w", err)
+	}
+
+	b.mu.Lock()
+	b.domains = domains
+	b.modTime = info.ModTime()
+	b.mu.Unlock()
+
+	zlog.Logger.Info().Str("path", b.path).Int("domains", len(domains)).Msg("blocklist loaded")
+	return nil
+}
@@ -0,0 +1,8 @@
+package webhook
+
+import (
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// HTTPSender implements ports.WebhookSender interface
+var _ ports.WebhookSender = (*HTTPSender)(nil)
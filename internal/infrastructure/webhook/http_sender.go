@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	userAgent      = "URLShortener-Webhook/1.0"
+	maxDrainBytes  = 64 << 10
+)
+
+// Headers sent with every delivery
+const (
+	HeaderDelivery  = "X-Webhook-ID"        // delivery id, the same on every retry
+	HeaderEvent     = "X-Webhook-Event"     // e.g. link.clicked
+	HeaderTimestamp = "X-Webhook-Timestamp" // unix seconds, signed with the body
+	HeaderSignature = "X-Webhook-Signature" // sha256=<hex HMAC>
+)
+
+// HTTPSender POSTs deliveries as JSON. Receivers verify them by computing
+// Sign with their secret and comparing it to the X-Webhook-Signature header.
+// Redirects are not followed, a delivery goes to the configured URL only.
+// The webhook URL is vetted when it is created, but its host may resolve
+// elsewhere by the time of a delivery: pass a netguard client to refuse
+// private addresses at dial time.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(client *http.Client, timeout time.Duration) Sender {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	var c http.Client
+	if client != nil {
+		c = *client
+	}
+	if c.Timeout == 0 {
+		c.Timeout = timeout
+	}
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &HTTPSender{client: &c}
+}
+
+// Send reports an error unless the receiver answered 2xx
+func (s *HTTPSender) Send(ctx context.Context, url, secret string, d *domain.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// draining lets the connection be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret.
+// Signing the timestamp lets receivers reject replayed deliveries.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/infrastructure/netguard"
+)
+
+const testSecret = "whsec_test"
+
+func testDelivery() *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:        981,
+		WebhookID: 7,
+		Event:     domain.EventLinkClicked,
+		Payload:   []byte(`{"event":"link.clicked"}`),
+	}
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	d := testDelivery()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != string(d.Payload) {
+			t.Errorf("body = %s, want %s", body, d.Payload)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if got := r.Header.Get(HeaderDelivery); got != "981" {
+			t.Errorf("%s = %q, want 981", HeaderDelivery, got)
+		}
+		if got := r.Header.Get(HeaderEvent); got != domain.EventLinkClicked {
+			t.Errorf("%s = %q, want %s", HeaderEvent, got, domain.EventLinkClicked)
+		}
+
+		timestamp := r.Header.Get(HeaderTimestamp)
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(ts, 0)).Abs() > time.Minute {
+			t.Errorf("%s = %q, want the current unix time", HeaderTimestamp, timestamp)
+		}
+		if got, want := r.Header.Get(HeaderSignature), "sha256="+Sign(testSecret, timestamp, body); got != want {
+			t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	status, err := NewHTTPSender(srv.Client(), 0).Send(context.Background(), srv.URL, testSecret, d)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if status != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", status, http.StatusAccepted)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1760781600.{}' | openssl dgst -sha256 -hmac secret
+	const want = "6e39060056a659e60ad32af771864dbfb099f8907d83fba9f8697dc8a0c014a6"
+	got := Sign("secret", "1760781600", []byte("{}"))
+	if got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+	if Sign("secret", "1760781601", []byte("{}")) == got {
+		t.Error("signature does not cover the timestamp")
+	}
+	if Sign("other", "1760781600", []byte("{}")) == got {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestSendReportsFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+	}{
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			status:  http.StatusInternalServerError,
+		},
+		{
+			name: "redirect is not followed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					t.Errorf("redirect was followed to %s", r.URL.Path)
+				}
+				http.Redirect(w, r, "/elsewhere", http.StatusTemporaryRedirect)
+			},
+			status: http.StatusTemporaryRedirect,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			status, err := NewHTTPSender(srv.Client(), 0).Send(context.Background(), srv.URL, testSecret, testDelivery())
+			if err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestSendRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivery reached a loopback receiver")
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPSender(netguard.Client(false), 0).Send(context.Background(), srv.URL, testSecret, testDelivery())
+	if !errors.Is(err, netguard.ErrPrivateAddress) {
+		t.Fatalf("Send error = %v, want ErrPrivateAddress", err)
+	}
+}
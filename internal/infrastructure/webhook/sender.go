@@ -0,0 +1,11 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+type Sender interface {
+	Send(ctx context.Context, url, secret string, delivery *domain.WebhookDelivery) (int, error)
+}
@@ -5,4 +5,23 @@ const (
 	DefaultRecentClicksLimit = 50
 	MaxRecentClicksLimit     = 1000
 	DefaultAnalyticsDaysBack = 30
+	PreviewSuffix            = "+"
+	VariantCookiePrefix      = "ab_"
+	VariantCookieMaxAge      = 30 * 24 * 60 * 60
+	ProceedCookiePrefix      = "warn_ok_"
+	ProceedCookieMaxAge      = 60 * 60
+	DefaultUnhealthyLimit    = 100
+	MaxUnhealthyLimit        = 1000
+	MaxUnhealthyFailures     = 1 << 20
+	SessionCookieName        = "session"
+	DefaultLinksLimit        = 50
+	MaxLinksLimit            = 500
+	MaxLinksOffset           = 1 << 20
+	WorkspaceHeader          = "X-Workspace-ID"
+	RequestIDHeader          = "X-Request-ID"
+	RequestIDContextKey      = "request_id"
+	DefaultAuditLimit        = 50
+	MaxAuditLimit            = 500
+	DefaultDeliveriesLimit   = 50
+	MaxDeliveriesLimit       = 500
 )
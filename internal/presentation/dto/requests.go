@@ -2,9 +2,100 @@ package dto
 
 // ShortenRequest - HTTP POST /shorten request body
 type ShortenRequest struct {
-	URL     string `json:"url" binding:"required"`
-	Custom  string `json:"custom"`
-	Expires int64  `json:"expires"`
+	URL      string           `json:"url" binding:"required"`
+	Custom   string           `json:"custom"`
+	Expires  int64            `json:"expires"`
+	Variants []VariantRequest `json:"variants"`
+	Sticky   string           `json:"sticky"` // "cookie" (default) or "ip"
+
+	ForwardQuery    bool `json:"forward_query"`
+	PathPassthrough bool `json:"path_passthrough"`
+
+	UTM     UTMRequest `json:"utm"`
+	UTMMode string     `json:"utm_mode"` // "merge" (default) or "override"
+
+	RedirectCode int `json:"redirect_code"` // 301, 302, 307 or 308, 0 for the server default
+
+	FallbackURL string `json:"fallback_url"` // where to send visitors once the destination is broken
+
+	ReuseExisting *bool `json:"reuse_existing"` // return an existing link to the same URL, omitted uses the server default
+
+	Signed bool `json:"signed"` // return a tamper-proof token instead of a plain code
+
+	Domain string `json:"domain"` // verified custom domain of the workspace, omitted for the service's own
+}
+
+// UTMRequest - campaign tags applied to the destination at redirect time
+type UTMRequest struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium"`
+	Campaign string `json:"campaign"`
+	Term     string `json:"term"`
+	Content  string `json:"content"`
+}
+
+// VariantRequest - one weighted destination of an A/B split link
+type VariantRequest struct {
+	Label  string `json:"label"`
+	URL    string `json:"url" binding:"required"`
+	Weight int    `json:"weight" binding:"required"`
+}
+
+// SetRiskFlagRequest - HTTP PUT /admin/links/:short/risk request body
+type SetRiskFlagRequest struct {
+	Flag string `json:"flag"` // empty clears the flag
+}
+
+// UpdateLinkRequest - HTTP PATCH /links/:short request body, omitted fields stay unchanged
+type UpdateLinkRequest struct {
+	URL          *string `json:"url"`
+	Expires      *int64  `json:"expires"`
+	FallbackURL  *string `json:"fallback_url"`  // empty removes the fallback
+	RedirectCode *int    `json:"redirect_code"` // 0 for the server default
+	Disabled     *bool   `json:"disabled"`
+}
+
+// CreateWebhookRequest - HTTP POST /webhooks request body
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"` // link.clicked, link.created, link.expired, link.disabled, omitted for all
+	Short  string   `json:"short"`  // only events of this link, omitted for the whole workspace
+	Domain string   `json:"domain"` // custom domain of that link, omitted for the service's own
+}
+
+// AddDomainRequest - HTTP POST /domains request body
+type AddDomainRequest struct {
+	Host string `json:"host" binding:"required"`
+}
+
+// ReservedWordRequest - HTTP POST /admin/reserved-words request body
+type ReservedWordRequest struct {
+	Word string `json:"word" binding:"required"`
+}
+
+// IssueAPIKeyRequest - HTTP POST /admin/api-keys request body
+type IssueAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"` // links:write, analytics:read, admin
+
+	WorkspaceID int64 `json:"workspace_id"` // bind the key to one of the caller's workspaces
+}
+
+// CredentialsRequest - HTTP POST /auth/login and /auth/register request body
+type CredentialsRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// CreateWorkspaceRequest - HTTP POST /workspaces request body
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// SetMemberRequest - HTTP PUT /workspaces/:id/members request body
+type SetMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"` // owner, editor, viewer
 }
 
 // AnalyticsDetailedQueryParams - HTTP query parameters for detailed analytics
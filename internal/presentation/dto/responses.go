@@ -1,8 +1,11 @@
 package dto
 
+import "encoding/json"
+
 type ShortenResponse struct {
 	Short   string `json:"short"`
 	Expires int64  `json:"expires"`
+	Reused  bool   `json:"reused"`
 }
 
 type AnalyticsResponse struct {
@@ -11,6 +14,27 @@ type AnalyticsResponse struct {
 	CreatedAt  int64  `json:"created_at"`
 	ExpiresAt  int64  `json:"expires_at"`
 	VisitCount int64  `json:"visit_count"`
+
+	RiskFlag          string `json:"risk_flag,omitempty"`
+	WarningsShown     int64  `json:"warnings_shown"`
+	WarningsProceeded int64  `json:"warnings_proceeded"`
+}
+
+type WarningResponse struct {
+	Short         string `json:"short"`
+	RiskFlag      string `json:"risk_flag"`
+	Destination   string `json:"destination"`
+	ProceedURL    string `json:"proceed_url"`
+	ProceedTicket string `json:"proceed_ticket"` // posted to ProceedURL as the ticket form field
+}
+
+type PreviewResponse struct {
+	Short       string `json:"short"`
+	Original    string `json:"original"`
+	CreatedAt   int64  `json:"created_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+	VisitCount  int64  `json:"visit_count"`
+	ContinueURL string `json:"continue_url"`
 }
 
 type DetailedAnalyticsResponse struct {
@@ -19,6 +43,113 @@ type DetailedAnalyticsResponse struct {
 	DeviceStats      map[string]int64 `json:"device_stats"`
 	MobilePercentage int              `json:"mobile_percentage"`
 	TotalClicks      int64            `json:"total_clicks"`
+	VariantStats     map[string]int64 `json:"variant_stats,omitempty"`
+}
+
+type CampaignAnalyticsResponse struct {
+	Campaigns []CampaignData `json:"campaigns"`
+}
+
+type CampaignData struct {
+	Campaign string   `json:"campaign"`
+	Links    int64    `json:"links"`
+	Visits   int64    `json:"visits"`
+	Shorts   []string `json:"shorts"`
+}
+
+type UnhealthyLinksResponse struct {
+	Links []UnhealthyLinkData `json:"links"`
+}
+
+type UnhealthyLinkData struct {
+	Short       string `json:"short"`
+	Original    string `json:"original"`
+	FallbackURL string `json:"fallback_url,omitempty"`
+	Status      int    `json:"status"`
+	LatencyMs   int64  `json:"latency_ms"`
+	Failures    int    `json:"failures"`
+	CheckedAt   int64  `json:"checked_at"`
+	BrokenSince int64  `json:"broken_since,omitempty"`
+}
+
+type ReservedWordsResponse struct {
+	Words []ReservedWordData `json:"words"`
+}
+
+type ReservedWordData struct {
+	Word      string `json:"word"`
+	Source    string `json:"source"` // route, config or admin
+	CreatedAt int64  `json:"created_at,omitempty"`
+}
+
+type APIKeysResponse struct {
+	Keys []APIKeyData `json:"keys"`
+}
+
+type APIKeyData struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"created_at"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+	Revoked    bool     `json:"revoked"`
+
+	WorkspaceID int64 `json:"workspace_id,omitempty"`
+}
+
+// IssuedAPIKeyResponse carries the key itself, which is not shown again
+type IssuedAPIKeyResponse struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Key       string   `json:"key"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+type UserResponse struct {
+	ID      int64  `json:"id"`
+	Email   string `json:"email"`
+	IsAdmin bool   `json:"is_admin"`
+}
+
+type LinksResponse struct {
+	Links []LinkData `json:"links"`
+}
+
+type LinkData struct {
+	Short      string `json:"short"`
+	Original   string `json:"original"`
+	CreatedAt  int64  `json:"created_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	VisitCount int64  `json:"visit_count"`
+	OwnerID    int64  `json:"owner_id,omitempty"`
+	Disabled   bool   `json:"disabled"`
+	Signed     bool   `json:"signed"`                // opened only through the token returned at creation
+	Domain     string `json:"domain,omitempty"`      // custom domain serving the link, omitted for the default one
+	ShortURL   string `json:"short_url,omitempty"`   // omitted for signed links, only their token opens them
+	PreviewURL string `json:"preview_url,omitempty"` // likewise
+}
+
+type WorkspacesResponse struct {
+	Workspaces []WorkspaceData `json:"workspaces"`
+}
+
+type WorkspaceData struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role,omitempty"` // the caller's role
+	CreatedAt int64  `json:"created_at"`
+}
+
+type MembersResponse struct {
+	Members []MemberData `json:"members"`
+}
+
+type MemberData struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
 }
 
 type RecentClicksResponse struct {
@@ -31,8 +162,73 @@ type ClickData struct {
 	IP         string `json:"ip"`
 	Referrer   string `json:"referrer"`
 	Device     string `json:"device"`
+	Variant    string `json:"variant,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+type AuditResponse struct {
+	Events []AuditEventData `json:"events"`
+}
+
+type AuditEventData struct {
+	ID          int64           `json:"id"`
+	Action      string          `json:"action"`
+	Short       string          `json:"short"`
+	LinkID      int64           `json:"link_id"`
+	WorkspaceID int64           `json:"workspace_id,omitempty"`
+	ActorUserID int64           `json:"actor_user_id,omitempty"`
+	ActorKeyID  int64           `json:"actor_key_id,omitempty"`
+	IP          string          `json:"ip,omitempty"`
+	RequestID   string          `json:"request_id,omitempty"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	CreatedAt   int64           `json:"created_at"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookData `json:"webhooks"`
+}
+
+type WebhookData struct {
+	ID          int64    `json:"id"`
+	WorkspaceID int64    `json:"workspace_id"`
+	Short       string   `json:"short,omitempty"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret,omitempty"` // only when created
+	CreatedAt   int64    `json:"created_at"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []DeliveryData `json:"deliveries"`
+}
+
+type DeliveryData struct {
+	ID            int64           `json:"id"`
+	Event         string          `json:"event"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastStatus    int             `json:"last_status_code,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt int64           `json:"next_attempt_at,omitempty"`
+	CreatedAt     int64           `json:"created_at"`
+	DeliveredAt   int64           `json:"delivered_at,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+type DomainsResponse struct {
+	Domains []DomainData `json:"domains"`
+}
+
+type DomainData struct {
+	ID                int64  `json:"id"`
+	Host              string `json:"host"`
+	Verified          bool   `json:"verified"`
+	VerifiedAt        int64  `json:"verified_at,omitempty"`
+	VerificationToken string `json:"verification_token"`
+	VerificationPath  string `json:"verification_path"` // the domain must answer it with the token before verifying
+	CreatedAt         int64  `json:"created_at"`
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/presentation"
+	presentationdto "github.com/yokitheyo/URLShortener/internal/presentation/dto"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+)
+
+// HandleRegister - HTTP POST /auth/register, signs the new user in
+func (h *URLHandler) HandleRegister(c *ginext.Context) {
+	var req presentationdto.CredentialsRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.accounts.SignUp(c.Request.Context(), req.Email, req.Password); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	h.login(c, req, http.StatusCreated)
+}
+
+// HandleLogin - HTTP POST /auth/login
+func (h *URLHandler) HandleLogin(c *ginext.Context) {
+	var req presentationdto.CredentialsRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	h.login(c, req, http.StatusOK)
+}
+
+func (h *URLHandler) login(c *ginext.Context, req presentationdto.CredentialsRequest, status int) {
+	user, token, expiresAt, err := h.accounts.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	setSessionCookie(c, token, expiresAt)
+	c.JSON(status, userResponse(user))
+}
+
+// HandleLogout - HTTP POST /auth/logout
+func (h *URLHandler) HandleLogout(c *ginext.Context) {
+	token, _ := c.Cookie(presentation.SessionCookieName)
+	if err := h.accounts.Logout(c.Request.Context(), token); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	setSessionCookie(c, "", time.Time{})
+	c.Status(http.StatusNoContent)
+}
+
+// HandleMe - HTTP GET /auth/me, the signed-in user
+func (h *URLHandler) HandleMe(c *ginext.Context) {
+	token, _ := c.Cookie(presentation.SessionCookieName)
+	user, err := h.accounts.AuthenticateSession(c.Request.Context(), token)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// HandleListLinks - HTTP GET /links?limit=50&offset=0&mine=true, links of the caller's workspace
+func (h *URLHandler) HandleListLinks(c *ginext.Context) {
+	mine, _ := strconv.ParseBool(c.Query("mine"))
+	query := dto.ListLinksQuery{
+		Actor:  middleware.ActorFromContext(c),
+		Mine:   mine,
+		Limit:  presentationutil.ParseLimit(c.Query("limit"), presentation.DefaultLinksLimit, presentation.MaxLinksLimit),
+		Offset: presentationutil.ParseLimit(c.Query("offset"), 0, presentation.MaxLinksOffset),
+	}
+
+	result, err := h.useCase.ListLinks(c.Request.Context(), query)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	links := make([]presentationdto.LinkData, len(result.Links))
+	for i, l := range result.Links {
+		links[i] = presentationdto.LinkData{
+			Short:      l.Short,
+			Original:   l.Original,
+			CreatedAt:  l.CreatedAt.Unix(),
+			ExpiresAt:  l.ExpiresAt.Unix(),
+			VisitCount: l.Visits,
+			OwnerID:    l.OwnerID,
+			Disabled:   l.Disabled,
+			Signed:     l.Signed,
+			Domain:     l.Domain,
+		}
+		if !l.Signed {
+			links[i].ShortURL = h.links.ShortURL(c, l.Domain, l.Short)
+			links[i].PreviewURL = h.links.PreviewURL(c, l.Domain, l.Short)
+		}
+	}
+
+	c.JSON(http.StatusOK, presentationdto.LinksResponse{Links: links})
+}
+
+func userResponse(u *domain.User) presentationdto.UserResponse {
+	return presentationdto.UserResponse{
+		ID:      u.ID,
+		Email:   u.Email,
+		IsAdmin: u.IsAdmin,
+	}
+}
+
+// setSessionCookie keeps the session out of reach of scripts and of cross-site POSTs,
+// an empty token deletes the cookie
+func setSessionCookie(c *ginext.Context, token string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     presentation.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+	if token == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
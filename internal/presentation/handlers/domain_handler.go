@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	presentationdto "github.com/yokitheyo/URLShortener/internal/presentation/dto"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+)
+
+// HandleListDomains - HTTP GET /domains, custom domains of the caller's workspace
+func (h *URLHandler) HandleListDomains(c *ginext.Context) {
+	domains, err := h.domains.List(c.Request.Context(), middleware.ActorFromContext(c))
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.DomainsResponse{Domains: make([]presentationdto.DomainData, len(domains))}
+	for i, d := range domains {
+		resp.Domains[i] = domainData(d)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleAddDomain - HTTP POST /domains, the domain stays unusable until verified
+func (h *URLHandler) HandleAddDomain(c *ginext.Context) {
+	var req presentationdto.AddDomainRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.domains.Add(c.Request.Context(), middleware.ActorFromContext(c), req.Host)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, domainData(d))
+}
+
+// HandleVerifyDomain - HTTP POST /domains/:id/verify
+func (h *URLHandler) HandleVerifyDomain(c *ginext.Context) {
+	id, ok := domainIDParam(c)
+	if !ok {
+		return
+	}
+
+	d, err := h.domains.Verify(c.Request.Context(), middleware.ActorFromContext(c), id)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domainData(d))
+}
+
+// HandleDeleteDomain - HTTP DELETE /domains/:id
+func (h *URLHandler) HandleDeleteDomain(c *ginext.Context) {
+	id, ok := domainIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.domains.Delete(c.Request.Context(), middleware.ActorFromContext(c), id); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func domainIDParam(c *ginext.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid domain id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func domainData(d *domain.CustomDomain) presentationdto.DomainData {
+	data := presentationdto.DomainData{
+		ID:                d.ID,
+		Host:              d.Host,
+		Verified:          d.Verified(),
+		VerificationToken: d.VerificationToken,
+		VerificationPath:  domain.DomainVerificationPath,
+		CreatedAt:         d.CreatedAt.Unix(),
+	}
+	if d.Verified() {
+		data.VerifiedAt = d.VerifiedAt.Unix()
+	}
+	return data
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/presentation"
+	presentationdto "github.com/yokitheyo/URLShortener/internal/presentation/dto"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+)
+
+// HandleUpdateLink - HTTP PATCH /links/:short, edits, disables or re-enables a link
+func (h *URLHandler) HandleUpdateLink(c *ginext.Context) {
+	var req presentationdto.UpdateLinkRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+	if req.URL != nil {
+		if err := h.urlValidator.ValidateURL(*req.URL); err != nil {
+			c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+			return
+		}
+	}
+
+	u, err := h.useCase.UpdateLink(c.Request.Context(), dto.UpdateLinkCommand{
+		Actor:        middleware.ActorFromContext(c),
+		Request:      presentationutil.BuildRequestMeta(c),
+		Short:        c.Param("short"),
+		Domain:       c.Query("domain"),
+		URL:          req.URL,
+		Expires:      req.Expires,
+		FallbackURL:  req.FallbackURL,
+		RedirectCode: req.RedirectCode,
+		Disabled:     req.Disabled,
+	})
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	host := domain.NormalizeHost(c.Query("domain"))
+	resp := presentationdto.LinkData{
+		Short:      u.Short,
+		Original:   u.Original,
+		CreatedAt:  u.CreatedAt.Unix(),
+		ExpiresAt:  u.ExpiresAt.Unix(),
+		VisitCount: u.Visits,
+		OwnerID:    u.OwnerID,
+		Disabled:   u.IsDisabled,
+		Signed:     u.Signed,
+		Domain:     host,
+	}
+	if !u.Signed {
+		resp.ShortURL = h.links.ShortURL(c, host, u.Short)
+		resp.PreviewURL = h.links.PreviewURL(c, host, u.Short)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleDeleteLink - HTTP DELETE /links/:short
+func (h *URLHandler) HandleDeleteLink(c *ginext.Context) {
+	cmd := dto.DeleteLinkCommand{
+		Actor:   middleware.ActorFromContext(c),
+		Request: presentationutil.BuildRequestMeta(c),
+		Short:   c.Param("short"),
+		Domain:  c.Query("domain"),
+	}
+
+	if err := h.useCase.DeleteLink(c.Request.Context(), cmd); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HandleLinkAudit - HTTP GET /links/:short/audit?limit=50&offset=0
+func (h *URLHandler) HandleLinkAudit(c *ginext.Context) {
+	events, err := h.audit.LinkHistory(c.Request.Context(), dto.AuditQuery{
+		Actor:  middleware.ActorFromContext(c),
+		Short:  c.Param("short"),
+		Domain: c.Query("domain"),
+		Limit:  presentationutil.ParseLimit(c.Query("limit"), presentation.DefaultAuditLimit, presentation.MaxAuditLimit),
+		Offset: presentationutil.ParseLimit(c.Query("offset"), 0, presentation.MaxLinksOffset),
+	})
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, auditResponse(events))
+}
+
+// HandleAuditLog - HTTP GET /admin/audit?workspace_id=&user_id=&short=&action=&from=2006-01-02&to=2006-01-02
+func (h *URLHandler) HandleAuditLog(c *ginext.Context) {
+	query := dto.AuditQuery{
+		Actor:  middleware.ActorFromContext(c),
+		Short:  c.Query("short"),
+		Action: c.Query("action"),
+		Limit:  presentationutil.ParseLimit(c.Query("limit"), presentation.DefaultAuditLimit, presentation.MaxAuditLimit),
+		Offset: presentationutil.ParseLimit(c.Query("offset"), 0, presentation.MaxLinksOffset),
+	}
+
+	var ok bool
+	if query.WorkspaceID, ok = idQuery(c, "workspace_id"); !ok {
+		return
+	}
+	if query.UserID, ok = idQuery(c, "user_id"); !ok {
+		return
+	}
+	if query.From, ok = dateQuery(c, "from"); !ok {
+		return
+	}
+	if query.To, ok = dateQuery(c, "to"); !ok {
+		return
+	}
+	if !query.To.IsZero() {
+		query.To = query.To.Add(24 * time.Hour) // include the whole day
+	}
+
+	events, err := h.audit.Search(c.Request.Context(), query)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, auditResponse(events))
+}
+
+// idQuery parses an optional id query parameter, answering 400 if it is malformed
+func idQuery(c *ginext.Context, name string) (int64, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// dateQuery parses an optional YYYY-MM-DD query parameter, answering 400 if it is malformed
+func dateQuery(c *ginext.Context, name string) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(presentation.DateFormat, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid " + name + ", use " + presentation.DateFormat})
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func auditResponse(events []*domain.AuditEvent) presentationdto.AuditResponse {
+	resp := presentationdto.AuditResponse{Events: make([]presentationdto.AuditEventData, len(events))}
+	for i, e := range events {
+		resp.Events[i] = presentationdto.AuditEventData{
+			ID:          e.ID,
+			Action:      e.Action,
+			Short:       e.Short,
+			LinkID:      e.LinkID,
+			WorkspaceID: e.WorkspaceID,
+			ActorUserID: e.ActorUserID,
+			ActorKeyID:  e.ActorKeyID,
+			IP:          e.IP,
+			RequestID:   e.RequestID,
+			Before:      e.Before,
+			After:       e.After,
+			CreatedAt:   e.CreatedAt.Unix(),
+		}
+	}
+	return resp
+}
@@ -1,26 +1,58 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/wb-go/wbf/ginext"
 	"github.com/yokitheyo/URLShortener/internal/application/dto"
 	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+	"github.com/yokitheyo/URLShortener/internal/domain"
 	"github.com/yokitheyo/URLShortener/internal/presentation"
 	presentationdto "github.com/yokitheyo/URLShortener/internal/presentation/dto"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
 	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+	"github.com/yokitheyo/URLShortener/internal/presentation/validation"
 )
 
 type URLHandler struct {
-	useCase *usecase.URLShortenerUseCase
+	useCase       *usecase.URLShortenerUseCase
+	apiKeys       *usecase.APIKeyService
+	accounts      *usecase.AccountService
+	workspaces    *usecase.WorkspaceService
+	audit         *usecase.AuditService
+	webhooks      *usecase.WebhookService
+	domains       *usecase.DomainService
+	links         *presentationutil.PublicURLs
+	urlValidator  *validation.URLValidator
+	codeValidator *validation.ShortCodeValidator
 }
 
-func NewURLHandler(uc *usecase.URLShortenerUseCase) *URLHandler {
+func NewURLHandler(uc *usecase.URLShortenerUseCase, apiKeys *usecase.APIKeyService, accounts *usecase.AccountService, workspaces *usecase.WorkspaceService, audit *usecase.AuditService, webhooks *usecase.WebhookService, domains *usecase.DomainService, links *presentationutil.PublicURLs) *URLHandler {
 	return &URLHandler{
-		useCase: uc,
+		useCase:       uc,
+		apiKeys:       apiKeys,
+		accounts:      accounts,
+		workspaces:    workspaces,
+		audit:         audit,
+		webhooks:      webhooks,
+		domains:       domains,
+		links:         links,
+		urlValidator:  validation.NewURLValidator(),
+		codeValidator: validation.NewShortCodeValidator(),
 	}
 }
 
+// HandleIndex - HTTP GET /
+func (h *URLHandler) HandleIndex(c *ginext.Context) {
+	c.HTML(http.StatusOK, "index.html", ginext.H{
+		"BasePath": h.links.BasePath(c),
+	})
+}
+
 // HandleShorten - HTTP POST /shorten
 func (h *URLHandler) HandleShorten(c *ginext.Context) {
 	var req presentationdto.ShortenRequest
@@ -29,10 +61,55 @@ func (h *URLHandler) HandleShorten(c *ginext.Context) {
 		return
 	}
 
+	if err := h.urlValidator.ValidateURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+	for _, v := range req.Variants {
+		if err := h.urlValidator.ValidateURL(v.URL); err != nil {
+			c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.codeValidator.ValidateShortCode(req.Custom); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid custom short code: " + err.Error()})
+		return
+	}
+
 	cmd := dto.ShortenCommand{
-		URL:     req.URL,
-		Custom:  req.Custom,
-		Expires: req.Expires,
+		Actor:      middleware.ActorFromContext(c),
+		Request:    presentationutil.BuildRequestMeta(c),
+		URL:        req.URL,
+		Custom:     req.Custom,
+		Expires:    req.Expires,
+		StickyMode: req.Sticky,
+
+		ForwardQuery:    req.ForwardQuery,
+		PathPassthrough: req.PathPassthrough,
+
+		UTM: dto.UTMSpec{
+			Source:   req.UTM.Source,
+			Medium:   req.UTM.Medium,
+			Campaign: req.UTM.Campaign,
+			Term:     req.UTM.Term,
+			Content:  req.UTM.Content,
+		},
+		UTMMode: req.UTMMode,
+
+		RedirectCode: req.RedirectCode,
+		FallbackURL:  req.FallbackURL,
+
+		ReuseExisting: req.ReuseExisting,
+
+		Signed: req.Signed,
+		Domain: req.Domain,
+	}
+	for _, v := range req.Variants {
+		cmd.Variants = append(cmd.Variants, dto.VariantSpec{
+			Label:  v.Label,
+			URL:    v.URL,
+			Weight: v.Weight,
+		})
 	}
 
 	result, err := h.useCase.Shorten(c.Request.Context(), cmd)
@@ -43,31 +120,337 @@ func (h *URLHandler) HandleShorten(c *ginext.Context) {
 	}
 
 	c.JSON(http.StatusOK, ginext.H{
-		"short":   result.Short,
-		"expires": result.ExpiresAt.Unix(),
+		"short":       result.Short,
+		"short_url":   h.links.ShortURL(c, result.Domain, result.Short),
+		"preview_url": h.links.PreviewURL(c, result.Domain, result.Short),
+		"expires":     result.ExpiresAt.Unix(),
+		"reused":      result.Reused,
 	})
 }
 
-// HandleRedirect - HTTP GET /s/:short
+// HandleRedirect - HTTP GET|HEAD /s/:short and /s/:short/*rest.
+// HEAD resolves the destination without recording a click.
 func (h *URLHandler) HandleRedirect(c *ginext.Context) {
 	short := c.Param("short")
+	if strings.HasSuffix(short, presentation.PreviewSuffix) && c.Param("rest") == "" {
+		h.renderPreview(c, strings.TrimSuffix(short, presentation.PreviewSuffix))
+		return
+	}
+	cookieName := presentation.VariantCookiePrefix + cookieKey(short)
+	variantCookie, _ := c.Cookie(cookieName)
+	proceedCookie, _ := c.Cookie(presentation.ProceedCookiePrefix + cookieKey(short))
 
-	meta := presentationutil.BuildClickMetadata(c)
+	cmd := dto.RedirectCommand{
+		Short:         short,
+		Host:          h.links.Host(c),
+		Meta:          presentationutil.BuildClickMetadata(c),
+		VariantCookie: variantCookie,
+		RawQuery:      c.Request.URL.RawQuery,
+		PathSuffix:    c.Param("rest"),
+		SkipClick:     c.Request.Method == http.MethodHead,
+		Proceed:       proceedCookie != "",
+	}
 
-	originalURL, err := h.useCase.Redirect(c.Request.Context(), short, meta)
+	result, err := h.useCase.Redirect(c.Request.Context(), cmd)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ginext.H{"error": "not found"})
+		if errors.Is(err, usecase.ErrInvalidPathSuffix) {
+			c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+			return
+		}
+		h.renderNotFound(c, short, err)
+		return
+	}
+
+	if result.Interstitial {
+		h.renderWarning(c, short, result)
+		return
+	}
+
+	if result.RememberVariant && result.Variant != variantCookie {
+		c.SetCookie(cookieName, result.Variant, presentation.VariantCookieMaxAge, h.links.BasePath(c)+"/s/"+url.PathEscape(short), "", false, true)
+	}
+
+	presentationutil.SetRedirectCacheHeaders(c, result)
+	c.Redirect(result.StatusCode, result.URL)
+}
+
+// renderNotFound answers for unknown, expired or disabled links: JSON for API
+// clients, the 404/410 page for browsers
+func (h *URLHandler) renderNotFound(c *ginext.Context, short string, err error) {
+	status := presentationutil.MapErrorToStatus(err)
+	if status != http.StatusGone {
+		status = http.StatusNotFound
+	}
+	c.Header("Cache-Control", "private, no-cache")
+
+	if presentationutil.WantsJSON(c) {
+		message := "not found"
+		if status == http.StatusGone {
+			message = err.Error()
+		}
+		c.JSON(status, ginext.H{"error": message})
+		return
+	}
+
+	c.HTML(status, "not_found.html", ginext.H{
+		"BasePath": h.links.BasePath(c),
+		"Short":    short,
+		"Gone":     status == http.StatusGone,
+		"Disabled": errors.Is(err, usecase.ErrDisabled),
+	})
+}
+
+func (h *URLHandler) renderWarning(c *ginext.Context, short string, result dto.RedirectResult) {
+	basePath := h.links.BasePath(c)
+	proceedURL := basePath + "/proceed/" + url.PathEscape(short)
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("X-Robots-Tag", "noindex")
+
+	if presentationutil.WantsJSON(c) {
+		c.JSON(http.StatusOK, presentationdto.WarningResponse{
+			Short:         short,
+			RiskFlag:      result.RiskFlag,
+			Destination:   result.URL,
+			ProceedURL:    proceedURL,
+			ProceedTicket: result.ProceedTicket,
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "warning.html", ginext.H{
+		"BasePath":    basePath,
+		"Short":       short,
+		"RiskFlag":    result.RiskFlag,
+		"Destination": result.URL,
+		"ProceedURL":  proceedURL,
+		"Ticket":      result.ProceedTicket,
+		"Next":        c.Request.URL.RequestURI(),
+	})
+}
+
+// HandleProceed - HTTP POST /proceed/:short
+// Counts the confirmation, remembers it in a cookie and sends the visitor back to the link.
+// ticket comes from the warning page, next is the link's path as the service saw it,
+// without the prefix of a reverse proxy.
+func (h *URLHandler) HandleProceed(c *ginext.Context) {
+	short := c.Param("short")
+
+	if err := h.useCase.ConfirmWarning(c.Request.Context(), h.links.Host(c), short, c.PostForm("ticket")); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	linkPath := "/s/" + url.PathEscape(short)
+	next := c.PostForm("next")
+	if !presentationutil.IsLinkPath(next, linkPath) {
+		next = linkPath
+	}
+
+	basePath := h.links.BasePath(c)
+	c.SetCookie(presentation.ProceedCookiePrefix+cookieKey(short), "1", presentation.ProceedCookieMaxAge, basePath+linkPath, "", false, true)
+	c.Redirect(http.StatusSeeOther, basePath+next)
+}
+
+// HandleSetRiskFlag - HTTP PUT /admin/links/:short/risk
+func (h *URLHandler) HandleSetRiskFlag(c *ginext.Context) {
+	var req presentationdto.SetRiskFlagRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	cmd := dto.SetRiskFlagCommand{
+		Actor:   middleware.ActorFromContext(c),
+		Request: presentationutil.BuildRequestMeta(c),
+		Short:   c.Param("short"),
+		Domain:  c.Query("domain"),
+		Flag:    req.Flag,
+	}
+
+	if err := h.useCase.SetRiskFlag(c.Request.Context(), cmd); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ginext.H{
+		"short":     cmd.Short,
+		"risk_flag": cmd.Flag,
+	})
+}
+
+// HandleListReservedWords - HTTP GET /admin/reserved-words
+func (h *URLHandler) HandleListReservedWords(c *ginext.Context) {
+	words, err := h.useCase.ListReservedWords(c.Request.Context(), middleware.ActorFromContext(c))
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.ReservedWordsResponse{Words: make([]presentationdto.ReservedWordData, len(words))}
+	for i, w := range words {
+		resp.Words[i] = presentationdto.ReservedWordData{
+			Word:   w.Word,
+			Source: w.Source,
+		}
+		if !w.CreatedAt.IsZero() {
+			resp.Words[i].CreatedAt = w.CreatedAt.Unix()
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleAddReservedWord - HTTP POST /admin/reserved-words
+func (h *URLHandler) HandleAddReservedWord(c *ginext.Context) {
+	var req presentationdto.ReservedWordRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.useCase.AddReservedWord(c.Request.Context(), middleware.ActorFromContext(c), req.Word); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ginext.H{"word": strings.ToLower(strings.TrimSpace(req.Word))})
+}
+
+// HandleRemoveReservedWord - HTTP DELETE /admin/reserved-words/:word
+func (h *URLHandler) HandleRemoveReservedWord(c *ginext.Context) {
+	if err := h.useCase.RemoveReservedWord(c.Request.Context(), middleware.ActorFromContext(c), c.Param("word")); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HandleListAPIKeys - HTTP GET /admin/api-keys
+func (h *URLHandler) HandleListAPIKeys(c *ginext.Context) {
+	keys, err := h.apiKeys.List(c.Request.Context())
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.APIKeysResponse{Keys: make([]presentationdto.APIKeyData, len(keys))}
+	for i, k := range keys {
+		resp.Keys[i] = presentationdto.APIKeyData{
+			ID:        k.ID,
+			Name:      k.Name,
+			Prefix:    k.Prefix,
+			Scopes:    k.Scopes,
+			CreatedAt: k.CreatedAt.Unix(),
+			Revoked:   k.IsRevoked(),
+
+			WorkspaceID: k.WorkspaceID,
+		}
+		if !k.LastUsedAt.IsZero() {
+			resp.Keys[i].LastUsedAt = k.LastUsedAt.Unix()
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleIssueAPIKey - HTTP POST /admin/api-keys
+func (h *URLHandler) HandleIssueAPIKey(c *ginext.Context) {
+	var req presentationdto.IssueAPIKeyRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	issued, err := h.apiKeys.Issue(c.Request.Context(), dto.IssueAPIKeyCommand{
+		Name:        req.Name,
+		Scopes:      req.Scopes,
+		UserID:      middleware.ActorFromContext(c).UserID,
+		WorkspaceID: req.WorkspaceID,
+	})
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, presentationdto.IssuedAPIKeyResponse{
+		ID:        issued.ID,
+		Name:      issued.Name,
+		Key:       issued.Key,
+		Scopes:    issued.Scopes,
+		CreatedAt: issued.CreatedAt.Unix(),
+	})
+}
+
+// HandleRevokeAPIKey - HTTP DELETE /admin/api-keys/:id
+func (h *URLHandler) HandleRevokeAPIKey(c *ginext.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid API key id"})
 		return
 	}
 
-	c.Redirect(http.StatusFound, originalURL)
+	if err := h.apiKeys.Revoke(c.Request.Context(), id); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HandlePreview - HTTP GET /p/:short (also reachable as /s/:short+).
+// Shows where a link leads without recording a click.
+func (h *URLHandler) HandlePreview(c *ginext.Context) {
+	h.renderPreview(c, c.Param("short"))
+}
+
+func (h *URLHandler) renderPreview(c *ginext.Context, short string) {
+	result, err := h.useCase.Preview(c.Request.Context(), h.links.Host(c), short)
+	if err != nil {
+		h.renderNotFound(c, short, usecase.ErrNotFound)
+		return
+	}
+
+	basePath := h.links.BasePath(c)
+	continueURL := basePath + "/s/" + url.PathEscape(result.Short)
+	c.Header("X-Robots-Tag", "noindex")
+
+	if presentationutil.WantsJSON(c) {
+		c.JSON(http.StatusOK, presentationdto.PreviewResponse{
+			Short:       result.Short,
+			Original:    result.Original,
+			CreatedAt:   result.CreatedAt.Unix(),
+			ExpiresAt:   result.ExpiresAt.Unix(),
+			VisitCount:  result.VisitCount,
+			ContinueURL: continueURL,
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "preview.html", ginext.H{
+		"BasePath":    basePath,
+		"Short":       result.Short,
+		"Original":    result.Original,
+		"CreatedAt":   result.CreatedAt,
+		"ExpiresAt":   result.ExpiresAt,
+		"VisitCount":  result.VisitCount,
+		"ContinueURL": continueURL,
+	})
 }
 
 // HandleAnalytics - HTTP GET /analytics/:short
 func (h *URLHandler) HandleAnalytics(c *ginext.Context) {
 	short := c.Param("short")
 
-	query := dto.AnalyticsQuery{Short: short}
+	query := dto.AnalyticsQuery{Actor: middleware.ActorFromContext(c), Short: short, Domain: c.Query("domain")}
 
 	result, err := h.useCase.GetAnalytics(c.Request.Context(), query)
 	if err != nil {
@@ -81,6 +464,10 @@ func (h *URLHandler) HandleAnalytics(c *ginext.Context) {
 		"created_at":  result.CreatedAt.Unix(),
 		"expires_at":  result.ExpiresAt.Unix(),
 		"visit_count": result.VisitCount,
+
+		"risk_flag":          result.RiskFlag,
+		"warnings_shown":     result.WarningsShown,
+		"warnings_proceeded": result.WarningsProceeded,
 	})
 }
 
@@ -94,9 +481,11 @@ func (h *URLHandler) HandleDetailedAnalytics(c *ginext.Context) {
 	from, to := presentationutil.ParseDateRange(fromStr, toStr)
 
 	query := dto.DetailedAnalyticsQuery{
-		Short: short,
-		From:  from,
-		To:    to,
+		Actor:  middleware.ActorFromContext(c),
+		Short:  short,
+		Domain: c.Query("domain"),
+		From:   from,
+		To:     to,
 	}
 
 	result, err := h.useCase.GetDetailedAnalytics(c.Request.Context(), query)
@@ -112,9 +501,66 @@ func (h *URLHandler) HandleDetailedAnalytics(c *ginext.Context) {
 		"device_stats":      result.DeviceStats,
 		"mobile_percentage": int(result.MobilePercentage),
 		"total_clicks":      result.TotalClicks,
+		"variant_stats":     result.VariantStats,
 	})
 }
 
+// HandleCampaignAnalytics - HTTP GET /campaigns
+func (h *URLHandler) HandleCampaignAnalytics(c *ginext.Context) {
+	result, err := h.useCase.GetCampaignAnalytics(c.Request.Context(), middleware.ActorFromContext(c))
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	campaigns := make([]presentationdto.CampaignData, len(result.Campaigns))
+	for i, cmp := range result.Campaigns {
+		campaigns[i] = presentationdto.CampaignData{
+			Campaign: cmp.Campaign,
+			Links:    cmp.Links,
+			Visits:   cmp.Visits,
+			Shorts:   cmp.Shorts,
+		}
+	}
+
+	c.JSON(http.StatusOK, presentationdto.CampaignAnalyticsResponse{Campaigns: campaigns})
+}
+
+// HandleUnhealthyLinks - HTTP GET /links/unhealthy
+func (h *URLHandler) HandleUnhealthyLinks(c *ginext.Context) {
+	query := dto.UnhealthyLinksQuery{
+		Actor:       middleware.ActorFromContext(c),
+		MinFailures: presentationutil.ParseLimit(c.Query("min_failures"), 1, presentation.MaxUnhealthyFailures),
+		Limit:       presentationutil.ParseLimit(c.Query("limit"), presentation.DefaultUnhealthyLimit, presentation.MaxUnhealthyLimit),
+	}
+
+	result, err := h.useCase.GetUnhealthyLinks(c.Request.Context(), query)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	links := make([]presentationdto.UnhealthyLinkData, len(result.Links))
+	for i, l := range result.Links {
+		links[i] = presentationdto.UnhealthyLinkData{
+			Short:       l.Short,
+			Original:    l.Original,
+			FallbackURL: l.FallbackURL,
+			Status:      l.Status,
+			LatencyMs:   l.LatencyMs,
+			Failures:    l.Failures,
+			CheckedAt:   l.CheckedAt.Unix(),
+		}
+		if !l.BrokenSince.IsZero() {
+			links[i].BrokenSince = l.BrokenSince.Unix()
+		}
+	}
+
+	c.JSON(http.StatusOK, presentationdto.UnhealthyLinksResponse{Links: links})
+}
+
 // HandleRecentClicks - HTTP GET /analytics/:short/recent-clicks
 func (h *URLHandler) HandleRecentClicks(c *ginext.Context) {
 	short := c.Param("short")
@@ -123,8 +569,10 @@ func (h *URLHandler) HandleRecentClicks(c *ginext.Context) {
 	limit := presentationutil.ParseLimit(limitStr, presentation.DefaultRecentClicksLimit, presentation.MaxRecentClicksLimit)
 
 	query := dto.RecentClicksQuery{
-		Short: short,
-		Limit: limit,
+		Actor:  middleware.ActorFromContext(c),
+		Short:  short,
+		Domain: c.Query("domain"),
+		Limit:  limit,
 	}
 
 	result, err := h.useCase.GetRecentClicks(c.Request.Context(), query)
@@ -139,3 +587,8 @@ func (h *URLHandler) HandleRecentClicks(c *ginext.Context) {
 		"total":  result.Total,
 	})
 }
+
+// cookieKey makes a short code safe for a cookie name, Unicode codes are not valid tokens
+func cookieKey(short string) string {
+	return url.PathEscape(domain.NormalizeShortCode(short))
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/presentation"
+	presentationdto "github.com/yokitheyo/URLShortener/internal/presentation/dto"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+)
+
+// HandleListWebhooks - HTTP GET /webhooks, webhooks of the caller's workspace
+func (h *URLHandler) HandleListWebhooks(c *ginext.Context) {
+	hooks, err := h.webhooks.List(c.Request.Context(), middleware.ActorFromContext(c))
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.WebhooksResponse{Webhooks: make([]presentationdto.WebhookData, len(hooks))}
+	for i, hook := range hooks {
+		resp.Webhooks[i] = webhookData(hook)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleCreateWebhook - HTTP POST /webhooks, the response holds the signing secret
+func (h *URLHandler) HandleCreateWebhook(c *ginext.Context) {
+	var req presentationdto.CreateWebhookRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	hook, err := h.webhooks.Create(c.Request.Context(), dto.CreateWebhookCommand{
+		Actor:  middleware.ActorFromContext(c),
+		URL:    req.URL,
+		Events: req.Events,
+		Short:  req.Short,
+		Domain: req.Domain,
+	})
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := webhookData(hook)
+	resp.Secret = hook.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// HandleDeleteWebhook - HTTP DELETE /webhooks/:id
+func (h *URLHandler) HandleDeleteWebhook(c *ginext.Context) {
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.Delete(c.Request.Context(), middleware.ActorFromContext(c), id); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HandleListDeliveries - HTTP GET /webhooks/:id/deliveries?status=dead&limit=50&offset=0
+func (h *URLHandler) HandleListDeliveries(c *ginext.Context) {
+	id, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(c.Request.Context(), dto.DeliveriesQuery{
+		Actor:     middleware.ActorFromContext(c),
+		WebhookID: id,
+		Status:    c.Query("status"),
+		Limit:     presentationutil.ParseLimit(c.Query("limit"), presentation.DefaultDeliveriesLimit, presentation.MaxDeliveriesLimit),
+		Offset:    presentationutil.ParseLimit(c.Query("offset"), 0, presentation.MaxLinksOffset),
+	})
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.DeliveriesResponse{Deliveries: make([]presentationdto.DeliveryData, len(deliveries))}
+	for i, d := range deliveries {
+		resp.Deliveries[i] = deliveryData(d)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func webhookIDParam(c *ginext.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid webhook id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func webhookData(hook *domain.Webhook) presentationdto.WebhookData {
+	return presentationdto.WebhookData{
+		ID:          hook.ID,
+		WorkspaceID: hook.WorkspaceID,
+		Short:       hook.Short,
+		URL:         hook.URL,
+		Events:      hook.Events,
+		CreatedAt:   hook.CreatedAt.Unix(),
+	}
+}
+
+func deliveryData(d *domain.WebhookDelivery) presentationdto.DeliveryData {
+	data := presentationdto.DeliveryData{
+		ID:         d.ID,
+		Event:      d.Event,
+		Status:     d.Status,
+		Attempts:   d.Attempts,
+		LastStatus: d.LastStatus,
+		LastError:  d.LastError,
+		CreatedAt:  d.CreatedAt.Unix(),
+		Payload:    d.Payload,
+	}
+	if d.Status == domain.DeliveryPending {
+		data.NextAttemptAt = d.NextAttemptAt.Unix()
+	}
+	if !d.DeliveredAt.IsZero() {
+		data.DeliveredAt = d.DeliveredAt.Unix()
+	}
+	return data
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	presentationdto "github.com/yokitheyo/URLShortener/internal/presentation/dto"
+	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+)
+
+// HandleListWorkspaces - HTTP GET /workspaces, workspaces of the calling user
+func (h *URLHandler) HandleListWorkspaces(c *ginext.Context) {
+	memberships, err := h.workspaces.List(c.Request.Context(), middleware.ActorFromContext(c))
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.WorkspacesResponse{Workspaces: make([]presentationdto.WorkspaceData, len(memberships))}
+	for i, m := range memberships {
+		resp.Workspaces[i] = workspaceData(m.Workspace, m.Role)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleCreateWorkspace - HTTP POST /workspaces, the caller becomes its owner
+func (h *URLHandler) HandleCreateWorkspace(c *ginext.Context) {
+	var req presentationdto.CreateWorkspaceRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	ws, err := h.workspaces.Create(c.Request.Context(), middleware.ActorFromContext(c), req.Name)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspaceData(*ws, domain.RoleOwner))
+}
+
+// HandleListMembers - HTTP GET /workspaces/:id/members
+func (h *URLHandler) HandleListMembers(c *ginext.Context) {
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaces.Members(c.Request.Context(), middleware.ActorFromContext(c), id)
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	resp := presentationdto.MembersResponse{Members: make([]presentationdto.MemberData, len(members))}
+	for i, m := range members {
+		resp.Members[i] = memberData(m)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleSetMember - HTTP PUT /workspaces/:id/members, adds a member or changes their role
+func (h *URLHandler) HandleSetMember(c *ginext.Context) {
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req presentationdto.SetMemberRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.workspaces.SetMember(c.Request.Context(), dto.SetMemberCommand{
+		Actor:       middleware.ActorFromContext(c),
+		WorkspaceID: id,
+		Email:       req.Email,
+		Role:        req.Role,
+	})
+	if err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, memberData(*member))
+}
+
+// HandleRemoveMember - HTTP DELETE /workspaces/:id/members/:user_id
+func (h *URLHandler) HandleRemoveMember(c *ginext.Context) {
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := h.workspaces.RemoveMember(c.Request.Context(), middleware.ActorFromContext(c), id, userID); err != nil {
+		status := presentationutil.MapErrorToStatus(err)
+		c.JSON(status, ginext.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func workspaceIDParam(c *ginext.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "invalid workspace id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func workspaceData(ws domain.Workspace, role string) presentationdto.WorkspaceData {
+	return presentationdto.WorkspaceData{
+		ID:        ws.ID,
+		Name:      ws.Name,
+		Role:      role,
+		CreatedAt: ws.CreatedAt.Unix(),
+	}
+}
+
+func memberData(m domain.Member) presentationdto.MemberData {
+	return presentationdto.MemberData{
+		UserID: m.UserID,
+		Email:  m.Email,
+		Role:   m.Role,
+	}
+}
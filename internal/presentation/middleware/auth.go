@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+	"github.com/yokitheyo/URLShortener/internal/presentation"
+	presentationutil "github.com/yokitheyo/URLShortener/internal/presentation/util"
+)
+
+const (
+	actorContextKey  = "actor"
+	apiKeyContextKey = "api_key_id"
+)
+
+// APIKeyAuthenticator resolves a bearer token to an API key
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*domain.APIKey, error)
+}
+
+// SessionAuthenticator resolves a session cookie to a user
+type SessionAuthenticator interface {
+	AuthenticateSession(ctx context.Context, token string) (*domain.User, error)
+}
+
+// WorkspaceResolver picks the workspace a request works in, 0 asks for the default one
+type WorkspaceResolver interface {
+	Resolve(ctx context.Context, actor domain.Actor, workspaceID int64) (domain.Actor, error)
+}
+
+// Auth identifies callers by an API key sent as "Authorization: Bearer <key>"
+// or by the web UI session cookie, and stores the resulting domain.Actor
+// working in the workspace selected by the X-Workspace-ID header.
+type Auth struct {
+	keys       APIKeyAuthenticator
+	sessions   SessionAuthenticator
+	workspaces WorkspaceResolver
+	enabled    bool
+}
+
+// NewAuth returns a guard. With enabled false, requests without credentials
+// pass as anonymous callers, the use cases decide what they may do. Admin
+// routes still need credentials.
+func NewAuth(keys APIKeyAuthenticator, sessions SessionAuthenticator, workspaces WorkspaceResolver, enabled bool) *Auth {
+	return &Auth{keys: keys, sessions: sessions, workspaces: workspaces, enabled: enabled}
+}
+
+// Require rejects requests without credentials granting scope: 401 without
+// valid credentials, 403 without the scope. An empty scope accepts any caller.
+func (a *Auth) Require(scope string) ginext.HandlerFunc {
+	return a.guard(scope, false)
+}
+
+// Optional lets anonymous requests through, the use case decides what they
+// may do. Credentials that are sent must still be valid and grant scope.
+func (a *Auth) Optional(scope string) ginext.HandlerFunc {
+	return a.guard(scope, true)
+}
+
+func (a *Auth) guard(scope string, optional bool) ginext.HandlerFunc {
+	return func(c *ginext.Context) {
+		actor, hasScope, err := a.identify(c)
+		if err != nil {
+			a.reject(c, err)
+			return
+		}
+
+		if hasScope == nil {
+			switch {
+			case !a.enabled && scope != domain.ScopeAdmin:
+				c.Next()
+				return
+			case !optional:
+				a.reject(c, usecase.ErrUnauthorized)
+				return
+			default:
+				c.Next()
+				return
+			}
+		} else if scope != "" && !hasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ginext.H{"error": usecase.ErrForbidden.Error() + ": requires " + scope})
+			return
+		}
+
+		actor, err = a.selectWorkspace(c, actor)
+		if err != nil {
+			a.reject(c, err)
+			return
+		}
+
+		c.Set(actorContextKey, actor)
+		c.Next()
+	}
+}
+
+// identify returns the caller and its scopes, hasScope is nil if no credentials were sent.
+// A session cookie that expired counts as no credentials.
+func (a *Auth) identify(c *ginext.Context) (domain.Actor, func(string) bool, error) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		token, ok := bearerToken(header)
+		if !ok {
+			return domain.Actor{}, nil, usecase.ErrUnauthorized
+		}
+		key, err := a.keys.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			return domain.Actor{}, nil, err
+		}
+		c.Set(apiKeyContextKey, key.ID)
+		return key.Actor(), key.HasScope, nil
+	}
+
+	if token, err := c.Cookie(presentation.SessionCookieName); err == nil && token != "" && a.sessions != nil {
+		user, err := a.sessions.AuthenticateSession(c.Request.Context(), token)
+		if errors.Is(err, usecase.ErrUnauthorized) {
+			return domain.Actor{}, nil, nil
+		}
+		if err != nil {
+			return domain.Actor{}, nil, err
+		}
+		return user.Actor(), user.HasScope, nil
+	}
+
+	return domain.Actor{}, nil, nil
+}
+
+// selectWorkspace puts actor into the workspace named by the X-Workspace-ID header,
+// or into its default workspace when the header is missing
+func (a *Auth) selectWorkspace(c *ginext.Context, actor domain.Actor) (domain.Actor, error) {
+	var workspaceID int64
+	if header := strings.TrimSpace(c.GetHeader(presentation.WorkspaceHeader)); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id <= 0 {
+			return domain.Actor{}, fmt.Errorf("%w: %s must be a workspace id", usecase.ErrInvalidWorkspace, presentation.WorkspaceHeader)
+		}
+		workspaceID = id
+	}
+	if a.workspaces == nil {
+		return actor, nil
+	}
+	return a.workspaces.Resolve(c.Request.Context(), actor, workspaceID)
+}
+
+func (a *Auth) reject(c *ginext.Context, err error) {
+	status := presentationutil.MapErrorToStatus(err)
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	}
+	c.AbortWithStatusJSON(status, ginext.H{"error": err.Error()})
+}
+
+// ActorFromContext returns who the request acts as, anonymous if nobody was identified
+func ActorFromContext(c *ginext.Context) domain.Actor {
+	v, ok := c.Get(actorContextKey)
+	if !ok {
+		return domain.Actor{}
+	}
+	actor, _ := v.(domain.Actor)
+	return actor
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
 	"time"
 
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/presentation"
 )
 
 // PanicRecoveryMiddleware recovers from panics
@@ -25,6 +29,28 @@ func PanicRecoveryMiddleware() ginext.HandlerFunc {
 	}
 }
 
+// RequestIDMiddleware tags each request with an ID, taken from X-Request-ID when
+// a proxy already set a sane one, and echoes it in the response
+func RequestIDMiddleware() ginext.HandlerFunc {
+	return func(c *ginext.Context) {
+		id := c.GetHeader(presentation.RequestIDHeader)
+		if !requestIDRegex.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(presentation.RequestIDContextKey, id)
+		c.Header(presentation.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // RequestLoggingMiddleware logs request details
 func RequestLoggingMiddleware() ginext.HandlerFunc {
 	return func(c *ginext.Context) {
@@ -34,6 +60,7 @@ func RequestLoggingMiddleware() ginext.HandlerFunc {
 
 		duration := time.Since(start)
 		zlog.Logger.Info().
+			Str("request_id", c.GetString(presentation.RequestIDContextKey)).
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
 			Int("status", c.Writer.Status()).
@@ -46,8 +73,9 @@ func RequestLoggingMiddleware() ginext.HandlerFunc {
 func CORSMiddleware() ginext.HandlerFunc {
 	return func(c *ginext.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+presentation.WorkspaceHeader+", "+presentation.RequestIDHeader)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, "+presentation.RequestIDHeader)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
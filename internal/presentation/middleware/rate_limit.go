@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+)
+
+// RateBudgets are the per-client budgets of the rate limited routes,
+// a budget with zero Requests is not limited
+type RateBudgets struct {
+	Shorten   ports.RateLimit
+	Analytics ports.RateLimit
+	Redirect  ports.RateLimit
+	Auth      ports.RateLimit
+}
+
+// RateLimiter answers 429 to clients over budget. Clients are told apart by
+// their API key once Auth identified it, otherwise by IP.
+type RateLimiter struct {
+	limiter ports.RateLimiter
+	budgets RateBudgets
+}
+
+// NewRateLimiter returns a limiter, a nil limiter lets every request through
+func NewRateLimiter(limiter ports.RateLimiter, budgets RateBudgets) *RateLimiter {
+	return &RateLimiter{limiter: limiter, budgets: budgets}
+}
+
+// Shorten limits link creation, put it after Auth so API keys are known
+func (r *RateLimiter) Shorten() ginext.HandlerFunc {
+	return r.limit("shorten", r.budgets.Shorten)
+}
+
+// Analytics limits analytics and link listings, put it after Auth
+func (r *RateLimiter) Analytics() ginext.HandlerFunc {
+	return r.limit("analytics", r.budgets.Analytics)
+}
+
+// Redirect limits redirects and previews, which are counted per IP
+func (r *RateLimiter) Redirect() ginext.HandlerFunc {
+	return r.limit("redirect", r.budgets.Redirect)
+}
+
+// Auth limits sign-ups and logins per IP, against password guessing and
+// sign-up spam
+func (r *RateLimiter) Auth() ginext.HandlerFunc {
+	return r.limit("auth", r.budgets.Auth)
+}
+
+func (r *RateLimiter) limit(name string, limit ports.RateLimit) ginext.HandlerFunc {
+	if r.limiter == nil || limit.Requests <= 0 || limit.Window <= 0 {
+		return func(c *ginext.Context) { c.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, int(math.Ceil(limit.Window.Seconds())))
+
+	return func(c *ginext.Context) {
+		decision, err := r.limiter.Allow(c.Request.Context(), name+":"+clientKey(c), limit)
+		if err != nil {
+			// better to serve without a limit than to fail every request
+			zlog.Logger.Warn().Err(err).Str("budget", name).Msg("rate limiter failed")
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", headerSeconds(decision.Reset))
+
+		if !decision.Allowed {
+			retry := headerSeconds(decision.RetryAfter)
+			c.Header("Retry-After", retry)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, ginext.H{"error": "rate limit exceeded, retry in " + retry + "s"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func clientKey(c *ginext.Context) string {
+	if id, ok := c.Get(apiKeyContextKey); ok {
+		return fmt.Sprintf("key:%d", id)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// headerSeconds rounds d up to whole seconds, as the headers carry
+func headerSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -1,7 +1,10 @@
 package router
 
 import (
+	"strings"
+
 	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/domain"
 	"github.com/yokitheyo/URLShortener/internal/presentation/handlers"
 	"github.com/yokitheyo/URLShortener/internal/presentation/middleware"
 )
@@ -9,17 +12,22 @@ import (
 type Router struct {
 	engine  *ginext.Engine
 	handler *handlers.URLHandler
+	auth    *middleware.Auth
+	limits  *middleware.RateLimiter
 }
 
-func NewRouter(engine *ginext.Engine, handler *handlers.URLHandler) *Router {
+func NewRouter(engine *ginext.Engine, handler *handlers.URLHandler, auth *middleware.Auth, limits *middleware.RateLimiter) *Router {
 	return &Router{
 		engine:  engine,
 		handler: handler,
+		auth:    auth,
+		limits:  limits,
 	}
 }
 
 func (r *Router) Setup() {
 	r.engine.Use(middleware.PanicRecoveryMiddleware())
+	r.engine.Use(middleware.RequestIDMiddleware())
 	r.engine.Use(middleware.RequestLoggingMiddleware())
 	r.engine.Use(middleware.CORSMiddleware())
 	r.engine.Use(ginext.Logger(), ginext.Recovery())
@@ -27,14 +35,88 @@ func (r *Router) Setup() {
 	r.engine.LoadHTMLGlob("templates/*")
 	r.engine.Static("/static", "./static")
 
-	r.engine.GET("/", func(c *ginext.Context) {
-		c.HTML(200, "index.html", nil)
-	})
+	r.engine.GET("/", r.handler.HandleIndex)
+
+	// redirects and previews stay public, everything else needs an API key or a session
+	// anonymous callers are let through, auth.anonymous_links decides if they may create links
+	// limits run after auth so clients with an API key are counted by key
+	r.engine.POST("/shorten", r.auth.Optional(domain.ScopeLinksWrite), r.limits.Shorten(), r.handler.HandleShorten)
+
+	redirect := r.limits.Redirect()
+	r.engine.GET("/s/:short", redirect, r.handler.HandleRedirect)
+	r.engine.GET("/s/:short/*rest", redirect, r.handler.HandleRedirect)
+	r.engine.HEAD("/s/:short", redirect, r.handler.HandleRedirect)
+	r.engine.HEAD("/s/:short/*rest", redirect, r.handler.HandleRedirect)
+	r.engine.GET("/p/:short", redirect, r.handler.HandlePreview)
+	r.engine.POST("/proceed/:short", redirect, r.handler.HandleProceed)
+
+	analytics := r.engine.Group("", r.auth.Require(domain.ScopeAnalyticsRead), r.limits.Analytics())
+	analytics.GET("/analytics/:short", r.handler.HandleAnalytics)
+	analytics.GET("/analytics/:short/detailed", r.handler.HandleDetailedAnalytics)
+	analytics.GET("/analytics/:short/recent-clicks", r.handler.HandleRecentClicks)
+	analytics.GET("/campaigns", r.handler.HandleCampaignAnalytics)
+	analytics.GET("/links", r.handler.HandleListLinks)
+	analytics.GET("/links/unhealthy", r.handler.HandleUnhealthyLinks)
+	analytics.GET("/links/:short/audit", r.handler.HandleLinkAudit)
+
+	// the workspace role decides further, viewers may not change links
+	links := r.engine.Group("/links", r.auth.Require(domain.ScopeLinksWrite), r.limits.Shorten())
+	links.PATCH("/:short", r.handler.HandleUpdateLink)
+	links.DELETE("/:short", r.handler.HandleDeleteLink)
+
+	account := r.engine.Group("/auth")
+	account.POST("/register", r.limits.Auth(), r.handler.HandleRegister)
+	account.POST("/login", r.limits.Auth(), r.handler.HandleLogin)
+	account.POST("/logout", r.handler.HandleLogout)
+	account.GET("/me", r.handler.HandleMe)
 
-	r.engine.POST("/shorten", r.handler.HandleShorten)
-	r.engine.GET("/s/:short", r.handler.HandleRedirect)
+	// roles, not key scopes, decide what members may do in a workspace
+	workspaces := r.engine.Group("/workspaces", r.auth.Require(""))
+	workspaces.GET("", r.handler.HandleListWorkspaces)
+	workspaces.POST("", r.handler.HandleCreateWorkspace)
+	workspaces.GET("/:id/members", r.handler.HandleListMembers)
+	workspaces.PUT("/:id/members", r.handler.HandleSetMember)
+	workspaces.DELETE("/:id/members/:user_id", r.handler.HandleRemoveMember)
 
-	r.engine.GET("/analytics/:short", r.handler.HandleAnalytics)
-	r.engine.GET("/analytics/:short/detailed", r.handler.HandleDetailedAnalytics)
-	r.engine.GET("/analytics/:short/recent-clicks", r.handler.HandleRecentClicks)
+	webhooks := r.engine.Group("/webhooks", r.auth.Require(""))
+	webhooks.GET("", r.handler.HandleListWebhooks)
+	webhooks.POST("", r.handler.HandleCreateWebhook)
+	webhooks.DELETE("/:id", r.handler.HandleDeleteWebhook)
+	webhooks.GET("/:id/deliveries", r.handler.HandleListDeliveries)
+
+	// like webhooks, a domain belongs to the caller's workspace
+	domains := r.engine.Group("/domains", r.auth.Require(""))
+	domains.GET("", r.handler.HandleListDomains)
+	domains.POST("", r.handler.HandleAddDomain)
+	domains.POST("/:id/verify", r.handler.HandleVerifyDomain)
+	domains.DELETE("/:id", r.handler.HandleDeleteDomain)
+
+	admin := r.engine.Group("/admin", r.auth.Require(domain.ScopeAdmin))
+	admin.PUT("/links/:short/risk", r.handler.HandleSetRiskFlag)
+	admin.GET("/audit", r.handler.HandleAuditLog)
+	admin.GET("/reserved-words", r.handler.HandleListReservedWords)
+	admin.POST("/reserved-words", r.handler.HandleAddReservedWord)
+	admin.DELETE("/reserved-words/:word", r.handler.HandleRemoveReservedWord)
+	admin.GET("/api-keys", r.handler.HandleListAPIKeys)
+	admin.POST("/api-keys", r.handler.HandleIssueAPIKey)
+	admin.DELETE("/api-keys/:id", r.handler.HandleRevokeAPIKey)
+}
+
+// RouteWords returns the distinct first path segments of all registered routes,
+// e.g. "admin" and "static", so they can be kept out of short codes
+func (r *Router) RouteWords() []string {
+	seen := make(map[string]struct{})
+	var words []string
+	for _, route := range r.engine.Routes() {
+		first := strings.SplitN(strings.TrimPrefix(route.Path, "/"), "/", 2)[0]
+		if first == "" || strings.HasPrefix(first, ":") || strings.HasPrefix(first, "*") {
+			continue
+		}
+		if _, ok := seen[first]; ok {
+			continue
+		}
+		seen[first] = struct{}{}
+		words = append(words, first)
+	}
+	return words
 }
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wb-go/wbf/ginext"
+	"github.com/yokitheyo/URLShortener/internal/application/dto"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// SetRedirectCacheHeaders lets clients cache permanent redirects until the link expires.
+// Temporary or visitor-dependent redirects must be revalidated so every click is counted.
+func SetRedirectCacheHeaders(c *ginext.Context, result dto.RedirectResult) {
+	if !result.Cacheable || !domain.IsPermanentRedirect(result.StatusCode) {
+		c.Header("Cache-Control", "private, no-cache")
+		return
+	}
+
+	maxAge := int(time.Until(result.ExpiresAt).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	c.Header("Expires", result.ExpiresAt.UTC().Format(http.TimeFormat))
+}
@@ -14,12 +14,45 @@ func MapErrorToStatus(err error) int {
 	}
 
 	switch {
-	case errors.Is(err, usecase.ErrShortCodeAlreadyExists):
+	case errors.Is(err, usecase.ErrShortCodeAlreadyExists),
+		errors.Is(err, usecase.ErrReservedWordExists),
+		errors.Is(err, usecase.ErrReservedWordBuiltin),
+		errors.Is(err, usecase.ErrEmailTaken),
+		errors.Is(err, usecase.ErrLastOwner),
+		errors.Is(err, usecase.ErrDomainTaken),
+		errors.Is(err, usecase.ErrDomainInUse):
 		return http.StatusConflict
+	case errors.Is(err, usecase.ErrUnsafeDestination),
+		errors.Is(err, usecase.ErrRedirectLoop),
+		errors.Is(err, usecase.ErrShortenerChain),
+		errors.Is(err, usecase.ErrDomainNotVerified):
+		return http.StatusUnprocessableEntity
 	case errors.Is(err, usecase.ErrInvalidCustomShort),
 		errors.Is(err, usecase.ErrURLRequired),
-		errors.Is(err, usecase.ErrInvalidQuery):
+		errors.Is(err, usecase.ErrInvalidQuery),
+		errors.Is(err, usecase.ErrInvalidVariants),
+		errors.Is(err, usecase.ErrInvalidUTM),
+		errors.Is(err, usecase.ErrInvalidRedirectCode),
+		errors.Is(err, usecase.ErrInvalidURL),
+		errors.Is(err, usecase.ErrInvalidRiskFlag),
+		errors.Is(err, usecase.ErrInvalidReservedWord),
+		errors.Is(err, usecase.ErrInvalidAPIKey),
+		errors.Is(err, usecase.ErrInvalidAccount),
+		errors.Is(err, usecase.ErrInvalidWorkspace),
+		errors.Is(err, usecase.ErrInvalidLinkUpdate),
+		errors.Is(err, usecase.ErrInvalidWebhook),
+		errors.Is(err, usecase.ErrSigningDisabled),
+		errors.Is(err, usecase.ErrInvalidDomain):
 		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrUnauthorized),
+		errors.Is(err, usecase.ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrForbidden),
+		errors.Is(err, usecase.ErrNoWarningShown):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrExpired),
+		errors.Is(err, usecase.ErrDisabled):
+		return http.StatusGone
 	case errors.Is(err, usecase.ErrNotFound),
 		errors.Is(err, usecase.ErrShortCodeRequired):
 		return http.StatusNotFound
@@ -0,0 +1,176 @@
+package util
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/wb-go/wbf/ginext"
+)
+
+const (
+	forwardedProtoHeader  = "X-Forwarded-Proto"
+	forwardedHostHeader   = "X-Forwarded-Host"
+	forwardedPrefixHeader = "X-Forwarded-Prefix"
+)
+
+// PublicURLOptions tell where the service is reachable
+type PublicURLOptions struct {
+	BaseURL        string   // scheme, host and optional prefix path, e.g. https://example.com/go; empty derives it per request
+	DomainScheme   string   // scheme of links on custom domains, https by default
+	TrustForwarded bool     // honour the X-Forwarded-Proto, -Host and -Prefix headers of a reverse proxy
+	TrustedProxies []string // IPs or CIDRs of those proxies, the headers of other peers are ignored
+}
+
+// PublicURLs builds the links the service hands out: absolute short and
+// preview URLs for API responses and prefixed paths for HTML pages
+type PublicURLs struct {
+	base           *url.URL
+	domainScheme   string
+	trustForwarded bool
+	proxies        []netip.Prefix
+}
+
+func NewPublicURLs(opts PublicURLOptions) (*PublicURLs, error) {
+	p := &PublicURLs{domainScheme: opts.DomainScheme, trustForwarded: opts.TrustForwarded}
+	if p.domainScheme != "http" {
+		p.domainScheme = "https"
+	}
+
+	if opts.BaseURL != "" {
+		base, err := url.Parse(opts.BaseURL)
+		if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+			return nil, fmt.Errorf("base URL %q must be an absolute http(s) URL", opts.BaseURL)
+		}
+		if base.RawQuery != "" || base.Fragment != "" {
+			return nil, fmt.Errorf("base URL %q must not have a query or fragment", opts.BaseURL)
+		}
+		p.base = &url.URL{Scheme: base.Scheme, Host: base.Host, Path: cleanPrefix(base.Path)}
+	}
+
+	for _, proxy := range opts.TrustedProxies {
+		prefix, err := parseProxy(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q must be an IP address or CIDR", proxy)
+		}
+		p.proxies = append(p.proxies, prefix)
+	}
+	return p, nil
+}
+
+// ShortURL is the absolute link to short, on host for a custom domain or on
+// the service's own domain if host is empty
+func (p *PublicURLs) ShortURL(c *ginext.Context, host, short string) string {
+	return p.linkBase(c, host) + "/s/" + url.PathEscape(short)
+}
+
+// PreviewURL is the absolute link to the preview page of short, like ShortURL
+func (p *PublicURLs) PreviewURL(c *ginext.Context, host, short string) string {
+	return p.linkBase(c, host) + "/p/" + url.PathEscape(short)
+}
+
+// BasePath is the prefix paths on pages served for c need, empty when the
+// service is served at the root of the request's host
+func (p *PublicURLs) BasePath(c *ginext.Context) string {
+	return p.origin(c).Path
+}
+
+// Host is the host the client reached the service on, it picks the domain
+// whose links a visitor asks for
+func (p *PublicURLs) Host(c *ginext.Context) string {
+	return p.origin(c).Host
+}
+
+func (p *PublicURLs) linkBase(c *ginext.Context, host string) string {
+	if host != "" {
+		return p.domainScheme + "://" + host
+	}
+	if p.base != nil {
+		return p.base.String()
+	}
+	return p.origin(c).String()
+}
+
+// origin is where the client reached the service: the request itself, as
+// rewritten by a trusted proxy. Without a forwarded prefix, requests on the
+// host of the base URL take its prefix path.
+func (p *PublicURLs) origin(c *ginext.Context) *url.URL {
+	o := &url.URL{Scheme: "http", Host: c.Request.Host}
+	if c.Request.TLS != nil {
+		o.Scheme = "https"
+	}
+
+	prefix, forwarded := "", false
+	if p.fromProxy(c) {
+		if proto := firstValue(c.GetHeader(forwardedProtoHeader)); proto == "http" || proto == "https" {
+			o.Scheme = proto
+		}
+		if host := firstValue(c.GetHeader(forwardedHostHeader)); host != "" {
+			o.Host = host
+		}
+		prefix = c.GetHeader(forwardedPrefixHeader)
+		forwarded = prefix != ""
+	}
+
+	if forwarded {
+		o.Path = cleanPrefix(firstValue(prefix))
+	} else if p.base != nil && strings.EqualFold(o.Host, p.base.Host) {
+		o.Path = p.base.Path
+	}
+	return o
+}
+
+// fromProxy reports whether c came straight from a trusted proxy, only then
+// the forwarded headers are not the client's own
+func (p *PublicURLs) fromProxy(c *ginext.Context) bool {
+	if !p.trustForwarded {
+		return false
+	}
+	peer, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return false
+	}
+	peer = peer.Unmap()
+	for _, proxy := range p.proxies {
+		if proxy.Contains(peer) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseProxy(proxy string) (netip.Prefix, error) {
+	proxy = strings.TrimSpace(proxy)
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// firstValue is the value the outermost proxy set in a comma separated header
+func firstValue(v string) string {
+	first, _, _ := strings.Cut(v, ",")
+	return strings.TrimSpace(first)
+}
+
+// cleanPrefix turns a prefix path into /a/b form, or empty for the root.
+// Anything that is not a plain absolute path is dropped so pages never link
+// off the site.
+func cleanPrefix(prefix string) string {
+	if !strings.HasPrefix(prefix, "/") || strings.HasPrefix(prefix, "//") || strings.ContainsAny(prefix, "\\?#") {
+		return ""
+	}
+	prefix = path.Clean(prefix)
+	if prefix == "/" {
+		return ""
+	}
+	return prefix
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wb-go/wbf/ginext"
+)
+
+type origin struct {
+	host, basePath, shortURL string
+}
+
+// serve runs req through a route that reports what p makes of it
+func serve(t *testing.T, p *PublicURLs, req *http.Request) origin {
+	t.Helper()
+	var got origin
+	engine := ginext.New("")
+	engine.GET("/*path", func(c *ginext.Context) {
+		got = origin{host: p.Host(c), basePath: p.BasePath(c), shortURL: p.ShortURL(c, "", "abc")}
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+func forwardedRequest(remoteAddr string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "http://sho.example/s/abc", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(forwardedProtoHeader, "https")
+	req.Header.Set(forwardedHostHeader, "go.victim.example")
+	req.Header.Set(forwardedPrefixHeader, "/evil")
+	return req
+}
+
+func TestForwardedHeadersNeedTrustedPeer(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PublicURLOptions
+		peer    string
+		want    origin
+		spoofed bool
+	}{
+		{
+			name: "trusted proxy",
+			opts: PublicURLOptions{TrustForwarded: true, TrustedProxies: []string{"10.0.0.0/8"}},
+			peer: "10.1.2.3:40000",
+			want: origin{host: "go.victim.example", basePath: "/evil", shortURL: "https://go.victim.example/evil/s/abc"},
+		},
+		{
+			name: "trusted proxy by address",
+			opts: PublicURLOptions{TrustForwarded: true, TrustedProxies: []string{"192.0.2.7"}},
+			peer: "192.0.2.7:40000",
+			want: origin{host: "go.victim.example", basePath: "/evil", shortURL: "https://go.victim.example/evil/s/abc"},
+		},
+		{
+			name: "untrusted peer",
+			opts: PublicURLOptions{TrustForwarded: true, TrustedProxies: []string{"10.0.0.0/8"}},
+			peer: "203.0.113.9:40000",
+			want: origin{host: "sho.example", shortURL: "http://sho.example/s/abc"},
+		},
+		{
+			name: "no proxies configured",
+			opts: PublicURLOptions{TrustForwarded: true},
+			peer: "10.1.2.3:40000",
+			want: origin{host: "sho.example", shortURL: "http://sho.example/s/abc"},
+		},
+		{
+			name: "forwarding off",
+			opts: PublicURLOptions{TrustedProxies: []string{"10.0.0.0/8"}},
+			peer: "10.1.2.3:40000",
+			want: origin{host: "sho.example", shortURL: "http://sho.example/s/abc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPublicURLs(tt.opts)
+			if err != nil {
+				t.Fatalf("NewPublicURLs: %v", err)
+			}
+			if got := serve(t, p, forwardedRequest(tt.peer)); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseURLPrefixOnItsHost(t *testing.T) {
+	p, err := NewPublicURLs(PublicURLOptions{BaseURL: "https://sho.example/go"})
+	if err != nil {
+		t.Fatalf("NewPublicURLs: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://sho.example/go/s/abc", nil)
+	want := origin{host: "sho.example", basePath: "/go", shortURL: "https://sho.example/go/s/abc"}
+	if got := serve(t, p, req); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestNewPublicURLsRejectsBadProxies(t *testing.T) {
+	for _, proxy := range []string{"proxy.internal", "10.0.0.0/33", ""} {
+		if _, err := NewPublicURLs(PublicURLOptions{TrustedProxies: []string{proxy}}); err == nil {
+			t.Errorf("trusted proxy %q accepted", proxy)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/wb-go/wbf/ginext"
 	"github.com/yokitheyo/URLShortener/internal/application/dto"
 	"github.com/yokitheyo/URLShortener/internal/infrastructure/geolocation"
+	"github.com/yokitheyo/URLShortener/internal/presentation"
 	"github.com/yokitheyo/URLShortener/internal/util"
 )
 
@@ -16,3 +17,11 @@ func BuildClickMetadata(c *ginext.Context) dto.ClickMetadata {
 		Device:    util.DetectDevice(c.Request.UserAgent()),
 	}
 }
+
+// BuildRequestMeta tells the audit log where a request came from
+func BuildRequestMeta(c *ginext.Context) dto.RequestMeta {
+	return dto.RequestMeta{
+		IP:        c.ClientIP(),
+		RequestID: c.GetString(presentation.RequestIDContextKey),
+	}
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/wb-go/wbf/ginext"
+)
+
+// WantsJSON reports whether the client asked for JSON instead of an HTML page,
+// either with ?format=json or an Accept header that prefers application/json.
+func WantsJSON(c *ginext.Context) bool {
+	switch c.Query("format") {
+	case "json":
+		return true
+	case "html":
+		return false
+	}
+
+	accept := c.GetHeader("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,14 @@
+package util
+
+import "strings"
+
+// IsLinkPath reports whether next is a local path pointing at linkPath (e.g. /s/abc),
+// optionally followed by a path suffix or query string. It guards redirects built
+// from user input against leaving the site.
+func IsLinkPath(next, linkPath string) bool {
+	if !strings.HasPrefix(next, linkPath) {
+		return false
+	}
+	rest := next[len(linkPath):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?'
+}
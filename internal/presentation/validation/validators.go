@@ -2,9 +2,9 @@ package validation
 
 import (
 	"errors"
-	"fmt"
-	"regexp"
 	"strings"
+
+	"github.com/yokitheyo/URLShortener/internal/domain"
 )
 
 type URLValidator struct{}
@@ -35,19 +35,10 @@ func NewShortCodeValidator() *ShortCodeValidator {
 	return &ShortCodeValidator{}
 }
 
+// ValidateShortCode applies the same limits as the use case, an empty code is allowed
 func (v *ShortCodeValidator) ValidateShortCode(code string) error {
 	if code == "" {
 		return nil
 	}
-
-	if len(code) < 3 || len(code) > 50 {
-		return fmt.Errorf("short code must be between 3 and 50 characters, got %d", len(code))
-	}
-
-	pattern := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
-	if !pattern.MatchString(code) {
-		return errors.New("short code can only contain letters, numbers, dashes and underscores")
-	}
-
-	return nil
+	return domain.ValidateShortCode(domain.NormalizeShortCode(code))
 }
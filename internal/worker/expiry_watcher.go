@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+)
+
+const (
+	defaultExpiryInterval  = 30 * time.Second
+	defaultExpiryBatchSize = 100
+)
+
+// ExpiryWatcherOptions tune the watcher, zero values fall back to defaults
+type ExpiryWatcherOptions struct {
+	Interval  time.Duration // pause between rounds that found nothing to do
+	BatchSize int           // links announced per round
+}
+
+// ExpiryWatcher announces links once they expire, as a LinkExpired event and
+// to webhooks. Several instances may run side by side, links are claimed with
+// row locks.
+type ExpiryWatcher struct {
+	useCase *usecase.URLShortenerUseCase
+	opts    ExpiryWatcherOptions
+}
+
+func NewExpiryWatcher(useCase *usecase.URLShortenerUseCase, opts ExpiryWatcherOptions) *ExpiryWatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultExpiryInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultExpiryBatchSize
+	}
+	return &ExpiryWatcher{useCase: useCase, opts: opts}
+}
+
+// Run announces expired links until ctx is done, full batches are followed
+// by the next one right away
+func (w *ExpiryWatcher) Run(ctx context.Context) {
+	for {
+		n, err := w.useCase.AnnounceExpired(ctx, w.opts.BatchSize)
+		if err != nil {
+			zlog.Logger.Warn().Err(err).Msg("failed to announce expired links")
+		}
+
+		wait := w.opts.Interval
+		if n == w.opts.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
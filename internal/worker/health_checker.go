@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+const (
+	defaultHealthInterval    = 10 * time.Minute
+	defaultHealthConcurrency = 8
+	defaultPerHostInterval   = 5 * time.Second
+	defaultHealthBatchSize   = 200
+)
+
+// HealthCheckerOptions tune the health checker, zero values fall back to defaults
+type HealthCheckerOptions struct {
+	Interval        time.Duration // how often a link is re-checked
+	Concurrency     int           // probes running at the same time
+	PerHostInterval time.Duration // minimum gap between probes to one host
+	BatchSize       int           // links picked up per round
+}
+
+// HealthChecker periodically probes the destinations of active links.
+// Links whose host was probed too recently are left for the next round.
+type HealthChecker struct {
+	useCase *usecase.URLShortenerUseCase
+	opts    HealthCheckerOptions
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+func NewHealthChecker(useCase *usecase.URLShortenerUseCase, opts HealthCheckerOptions) *HealthChecker {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultHealthInterval
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultHealthConcurrency
+	}
+	if opts.PerHostInterval <= 0 {
+		opts.PerHostInterval = defaultPerHostInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultHealthBatchSize
+	}
+
+	return &HealthChecker{
+		useCase:  useCase,
+		opts:     opts,
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// Run checks due links every tick until ctx is done. Rounds run more often
+// than Interval so a large backlog is spread out instead of burst at once.
+func (h *HealthChecker) Run(ctx context.Context) {
+	tick := h.opts.Interval / 10
+	if tick < time.Second {
+		tick = time.Second
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		h.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *HealthChecker) runOnce(ctx context.Context) {
+	now := time.Now()
+	links, err := h.useCase.LinksDueForHealthCheck(ctx, domain.SystemActor, now.Add(-h.opts.Interval), h.opts.BatchSize)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to load links for health check")
+		return
+	}
+
+	sem := make(chan struct{}, h.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, link := range links {
+		if !h.reserveHost(hostOf(link.Original), now) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := h.useCase.CheckLinkHealth(ctx, domain.SystemActor, link); err != nil {
+				zlog.Logger.Warn().Err(err).Str("short", link.Short).Msg("health check failed")
+			}
+		}()
+	}
+
+	wg.Wait()
+	h.forgetIdleHosts(now)
+}
+
+// reserveHost claims a probe slot for host unless it was probed within PerHostInterval
+func (h *HealthChecker) reserveHost(host string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if last, ok := h.lastSeen[host]; ok && now.Sub(last) < h.opts.PerHostInterval {
+		return false
+	}
+	h.lastSeen[host] = now
+	return true
+}
+
+func (h *HealthChecker) forgetIdleHosts(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for host, last := range h.lastSeen {
+		if now.Sub(last) >= h.opts.PerHostInterval {
+			delete(h.lastSeen, host)
+		}
+	}
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return strings.ToLower(u.Hostname())
+}
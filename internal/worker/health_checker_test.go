@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yokitheyo/URLShortener/internal/application/ports"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+	"github.com/yokitheyo/URLShortener/internal/domain"
+)
+
+// healthRepo hands out due links the way the postgres repository does:
+// round-robin over hosts, least recently checked first, then by id
+type healthRepo struct {
+	ports.URLRepository
+
+	mu    sync.Mutex
+	links []*domain.URL
+}
+
+func (r *healthRepo) ListForHealthCheck(_ context.Context, _ int64, checkedBefore time.Time, limit int) ([]*domain.URL, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*domain.URL
+	for _, u := range r.links {
+		if u.Health.CheckedAt.IsZero() || u.Health.CheckedAt.Before(checkedBefore) {
+			due = append(due, u)
+		}
+	}
+	byAge := func(a, b *domain.URL) int {
+		return cmp.Or(a.Health.CheckedAt.Compare(b.Health.CheckedAt), cmp.Compare(a.ID, b.ID))
+	}
+	slices.SortFunc(due, byAge)
+
+	rank := make(map[int64]int, len(due))
+	seen := make(map[string]int)
+	for _, u := range due {
+		host := hostOf(u.Original)
+		seen[host]++
+		rank[u.ID] = seen[host]
+	}
+	slices.SortStableFunc(due, func(a, b *domain.URL) int {
+		return cmp.Or(cmp.Compare(rank[a.ID], rank[b.ID]), byAge(a, b))
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	out := make([]*domain.URL, len(due))
+	for i, u := range due {
+		c := *u
+		out[i] = &c
+	}
+	return out, nil
+}
+
+func (r *healthRepo) UpdateHealth(_ context.Context, id int64, status int, _ int64, _ bool, checkedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.links {
+		if u.ID == id {
+			u.Health.Status = status
+			u.Health.CheckedAt = checkedAt
+		}
+	}
+	return nil
+}
+
+// countingProber answers 200 and counts probes per URL
+type countingProber struct {
+	mu     sync.Mutex
+	probes map[string]int
+}
+
+func (p *countingProber) Probe(_ context.Context, rawURL string) (int, time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.probes[rawURL]++
+	return 200, time.Millisecond, nil
+}
+
+func (p *countingProber) count(rawURL string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.probes[rawURL]
+}
+
+func TestHealthCheckerDoesNotStarveOtherHosts(t *testing.T) {
+	const batch = 20
+	repo := &healthRepo{}
+	for i := 1; i <= 5*batch; i++ {
+		repo.links = append(repo.links, &domain.URL{ID: int64(i), Original: fmt.Sprintf("https://youtube.com/watch?v=%d", i)})
+	}
+	others := []string{"https://example.com/a", "https://example.org/b", "https://example.net/c"}
+	for i, o := range others {
+		repo.links = append(repo.links, &domain.URL{ID: int64(1000 + i), Original: o})
+	}
+
+	prober := &countingProber{probes: make(map[string]int)}
+	uc := usecase.NewURLShortenerUseCase(repo, nil, nil).WithHealthProber(prober)
+	h := NewHealthChecker(uc, HealthCheckerOptions{Interval: time.Hour, PerHostInterval: time.Hour, BatchSize: batch})
+
+	h.runOnce(context.Background())
+
+	for _, o := range others {
+		if prober.count(o) != 1 {
+			t.Errorf("%s probed %d times in the first round, want once", o, prober.count(o))
+		}
+	}
+	youtube := 0
+	for _, u := range repo.links[:5*batch] {
+		youtube += prober.count(u.Original)
+	}
+	if youtube != 1 {
+		t.Errorf("youtube.com probed %d times, want once per round", youtube)
+	}
+}
+
+func TestHealthCheckerThrottlesHost(t *testing.T) {
+	repo := &healthRepo{links: []*domain.URL{
+		{ID: 1, Original: "https://example.com/a"},
+		{ID: 2, Original: "https://EXAMPLE.com/b"},
+	}}
+	prober := &countingProber{probes: make(map[string]int)}
+	uc := usecase.NewURLShortenerUseCase(repo, nil, nil).WithHealthProber(prober)
+	h := NewHealthChecker(uc, HealthCheckerOptions{Interval: time.Hour, PerHostInterval: time.Hour})
+
+	h.runOnce(context.Background())
+	h.runOnce(context.Background())
+
+	if got := prober.count("https://example.com/a") + prober.count("https://EXAMPLE.com/b"); got != 1 {
+		t.Errorf("host probed %d times within PerHostInterval, want once", got)
+	}
+	if prober.count("https://example.com/a") != 1 {
+		t.Error("the lower id was not probed first")
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+)
+
+const (
+	defaultRelayInterval  = time.Second
+	defaultRelayBatchSize = 500
+	relayPruneInterval    = time.Hour
+)
+
+// OutboxRelayOptions tune the relay, zero values fall back to defaults
+type OutboxRelayOptions struct {
+	Interval  time.Duration // pause between rounds that found nothing to do
+	BatchSize int           // events published per round
+}
+
+// OutboxRelay publishes domain events from the outbox to the event sink and
+// prunes the ones published long ago
+type OutboxRelay struct {
+	relay *usecase.EventRelay
+	opts  OutboxRelayOptions
+}
+
+func NewOutboxRelay(relay *usecase.EventRelay, opts OutboxRelayOptions) *OutboxRelay {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultRelayInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultRelayBatchSize
+	}
+	return &OutboxRelay{relay: relay, opts: opts}
+}
+
+// Run relays until ctx is done. A full batch is followed by the next one
+// right away, a failed one is retried after the interval.
+func (o *OutboxRelay) Run(ctx context.Context) {
+	pruned := time.Time{}
+
+	for {
+		n, err := o.relay.RelayBatch(ctx, o.opts.BatchSize)
+		if err != nil {
+			zlog.Logger.Warn().Err(err).Msg("failed to relay domain events")
+		}
+
+		if time.Since(pruned) >= relayPruneInterval {
+			if deleted, err := o.relay.Prune(ctx); err != nil {
+				zlog.Logger.Warn().Err(err).Msg("failed to prune published domain events")
+			} else if deleted > 0 {
+				zlog.Logger.Info().Int64("deleted", deleted).Msg("pruned published domain events")
+			}
+			pruned = time.Now()
+		}
+
+		wait := o.opts.Interval
+		if n == o.opts.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"github.com/yokitheyo/URLShortener/internal/application/usecase"
+)
+
+const (
+	defaultWebhookInterval    = 5 * time.Second
+	defaultWebhookConcurrency = 4
+	defaultWebhookBatchSize   = 100
+)
+
+// WebhookDispatcherOptions tune the dispatcher, zero values fall back to defaults
+type WebhookDispatcherOptions struct {
+	Interval    time.Duration // pause between rounds that found nothing to do
+	Concurrency int           // deliveries sent at the same time
+	BatchSize   int           // deliveries claimed per round
+}
+
+// WebhookDispatcher drains the webhook outbox. Several instances may run side by side, deliveries are claimed with row locks.
+type WebhookDispatcher struct {
+	webhooks *usecase.WebhookService
+	opts     WebhookDispatcherOptions
+}
+
+func NewWebhookDispatcher(webhooks *usecase.WebhookService, opts WebhookDispatcherOptions) *WebhookDispatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWebhookInterval
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultWebhookConcurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultWebhookBatchSize
+	}
+	return &WebhookDispatcher{webhooks: webhooks, opts: opts}
+}
+
+// Run delivers until ctx is done. A full batch is followed by the next one
+// right away, so a backlog drains without waiting for the interval.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	for {
+		busy := d.runOnce(ctx)
+
+		wait := d.opts.Interval
+		if busy {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runOnce reports whether there may be more work waiting
+func (d *WebhookDispatcher) runOnce(ctx context.Context) bool {
+	deliveries, err := d.webhooks.ClaimDueDeliveries(ctx, d.opts.BatchSize)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to claim webhook deliveries")
+		return false
+	}
+
+	sem := make(chan struct{}, d.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, delivery := range deliveries {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return false
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := d.webhooks.Deliver(ctx, delivery); err != nil {
+				zlog.Logger.Warn().Err(err).Int64("delivery_id", delivery.ID).Msg("webhook delivery failed")
+			}
+		}()
+	}
+
+	wg.Wait()
+	return len(deliveries) == d.opts.BatchSize
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_clicks_variant_id;
+
+ALTER TABLE clicks DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS url_variants CASCADE;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS sticky_mode;
@@ -0,0 +1,17 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS sticky_mode VARCHAR(10) NOT NULL DEFAULT 'cookie';
+
+CREATE TABLE IF NOT EXISTS url_variants (
+    id SERIAL PRIMARY KEY,
+    url_id INT NOT NULL REFERENCES urls (id) ON DELETE CASCADE,
+    label VARCHAR(32) NOT NULL,
+    destination TEXT NOT NULL,
+    weight INT NOT NULL CHECK (weight > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    UNIQUE (url_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_variants_url_id ON url_variants (url_id);
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES url_variants (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_variant_id ON clicks (variant_id);
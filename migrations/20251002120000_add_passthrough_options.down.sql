@@ -0,0 +1,3 @@
+ALTER TABLE urls DROP COLUMN IF EXISTS path_passthrough;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS forward_query;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_urls_utm_campaign;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_mode;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_content;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_term;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_campaign;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_medium;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_source;
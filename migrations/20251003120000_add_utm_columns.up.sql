@@ -0,0 +1,13 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_source VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_term VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_content VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_mode VARCHAR(10) NOT NULL DEFAULT 'merge';
+
+CREATE INDEX IF NOT EXISTS idx_urls_utm_campaign ON urls (utm_campaign) WHERE utm_campaign <> '';
@@ -0,0 +1,5 @@
+ALTER TABLE urls DROP COLUMN IF EXISTS warnings_proceeded;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS warnings_shown;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS risk_flag;
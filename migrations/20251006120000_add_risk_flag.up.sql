@@ -0,0 +1,5 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS risk_flag VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS warnings_shown INT NOT NULL DEFAULT 0;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS warnings_proceeded INT NOT NULL DEFAULT 0;
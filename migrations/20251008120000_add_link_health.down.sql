@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_urls_health_failures;
+
+DROP INDEX IF EXISTS idx_urls_health_checked_at;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS fallback_url;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS broken_since;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS health_checked_at;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS health_failures;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS health_latency_ms;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS health_status;
@@ -0,0 +1,15 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS health_status INT NOT NULL DEFAULT 0;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS health_latency_ms INT NOT NULL DEFAULT 0;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS health_failures INT NOT NULL DEFAULT 0;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS health_checked_at TIMESTAMP;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS broken_since TIMESTAMP;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS fallback_url TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_urls_health_checked_at ON urls (health_checked_at NULLS FIRST);
+
+CREATE INDEX IF NOT EXISTS idx_urls_health_failures ON urls (health_failures) WHERE health_failures > 0;
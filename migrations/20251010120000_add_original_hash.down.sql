@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_urls_original_hash;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS is_custom;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS original_hash;
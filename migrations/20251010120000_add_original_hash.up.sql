@@ -0,0 +1,5 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS original_hash CHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS is_custom BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_urls_original_hash ON urls (original_hash) WHERE original_hash <> '' AND NOT is_custom;
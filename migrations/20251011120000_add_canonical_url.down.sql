@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_urls_canonical;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS canonical;
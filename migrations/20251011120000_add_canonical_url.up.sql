@@ -0,0 +1,6 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS canonical TEXT NOT NULL DEFAULT '';
+
+-- best effort for existing links, new ones are canonicalized on shorten
+UPDATE urls SET canonical = original WHERE canonical = '';
+
+CREATE INDEX IF NOT EXISTS idx_urls_canonical ON urls (canonical);
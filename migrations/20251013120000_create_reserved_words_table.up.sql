@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS reserved_words (
+    word VARCHAR(64) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
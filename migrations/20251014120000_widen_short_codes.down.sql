@@ -0,0 +1,4 @@
+-- fails if codes longer than the old limits have been created since
+ALTER TABLE clicks ALTER COLUMN short TYPE VARCHAR(20);
+
+ALTER TABLE urls ALTER COLUMN short TYPE VARCHAR(10);
@@ -0,0 +1,3 @@
+ALTER TABLE urls ALTER COLUMN short TYPE VARCHAR(64);
+
+ALTER TABLE clicks ALTER COLUMN short TYPE VARCHAR(64);
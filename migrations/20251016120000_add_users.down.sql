@@ -0,0 +1,9 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS user_id;
+
+DROP INDEX IF EXISTS idx_urls_owner_id;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS sessions;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,9 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_urls_workspace_id;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id, workspace_id);
+
+-- links without a workspace (anonymous ones) are only visible to admins,
+-- short codes stay unique across workspaces
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS workspace_id BIGINT REFERENCES workspaces(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_urls_workspace_id ON urls (workspace_id, created_at DESC);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE;
+
+-- every existing account gets a personal workspace holding the links it owns
+INSERT INTO workspaces (name, created_at)
+SELECT u.email, u.created_at FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.user_id = u.id)
+ORDER BY u.id;
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+SELECT w.id, u.id, 'owner', w.created_at FROM users u
+JOIN workspaces w ON w.name = u.email
+WHERE NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id);
+
+UPDATE urls SET workspace_id = m.workspace_id
+FROM workspace_members m
+WHERE m.user_id = urls.owner_id AND urls.workspace_id IS NULL;
+
+UPDATE api_keys SET workspace_id = m.workspace_id
+FROM workspace_members m
+WHERE m.user_id = api_keys.user_id AND api_keys.workspace_id IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_urls_expiry_pending;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS expiry_notified;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,17 @@
+-- domain events are written here in the transaction that caused them and
+-- published to the event sink by the relay; ids are kept without foreign
+-- keys so events outlive the link they mention
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    link_id BIGINT NOT NULL,
+    short VARCHAR(64) NOT NULL,
+    workspace_id BIGINT,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    available_at TIMESTAMP NOT NULL DEFAULT now(),
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at) WHERE published_at IS NOT NULL;
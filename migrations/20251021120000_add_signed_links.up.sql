@@ -0,0 +1,2 @@
+-- signed links are only reachable through a token carrying an HMAC of the code and expiry
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS signed BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,9 @@
+-- codes repeated on several domains have to go before codes are unique again
+DELETE FROM urls WHERE domain_id IS NOT NULL;
+
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_domain_id_short_key;
+ALTER TABLE urls ADD CONSTRAINT urls_short_key UNIQUE (short);
+
+ALTER TABLE urls DROP COLUMN IF EXISTS domain_id;
+
+DROP TABLE IF EXISTS domains;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS domains (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    host VARCHAR(253) NOT NULL UNIQUE,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains (workspace_id, host);
+
+-- links without a domain are served on the service's own host; codes are
+-- unique per domain, so the same code may exist on several hosts
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS domain_id BIGINT REFERENCES domains(id) ON DELETE RESTRICT;
+
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_short_key;
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_domain_id_short_key;
+ALTER TABLE urls ADD CONSTRAINT urls_domain_id_short_key UNIQUE NULLS NOT DISTINCT (domain_id, short);
@@ -0,0 +1,12 @@
+-- pending claims of hosts claimed elsewhere have to go before hosts are unique again
+DELETE FROM domains d
+WHERE d.verified_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM urls WHERE urls.domain_id = d.id)
+  AND EXISTS (SELECT 1 FROM domains o WHERE o.host = d.host AND o.id <> d.id
+              AND (o.verified_at IS NOT NULL OR o.id < d.id));
+
+DROP INDEX IF EXISTS idx_domains_verified_host;
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_workspace_id_host_key;
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains (workspace_id, host);
+ALTER TABLE domains ADD CONSTRAINT domains_host_key UNIQUE (host);
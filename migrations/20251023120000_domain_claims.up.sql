@@ -0,0 +1,7 @@
+-- a host is only taken once it is verified, several workspaces may claim it
+-- until then, so a claim cannot squat a domain before its owner adds it
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_host_key;
+DROP INDEX IF EXISTS idx_domains_workspace_id;
+
+ALTER TABLE domains ADD CONSTRAINT domains_workspace_id_host_key UNIQUE (workspace_id, host);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_verified_host ON domains (host) WHERE verified_at IS NOT NULL;
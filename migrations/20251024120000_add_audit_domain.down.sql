@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_audit_events_domain_short;
+
+ALTER TABLE audit_events DROP COLUMN IF EXISTS domain_id;
@@ -0,0 +1,5 @@
+-- codes are unique per domain, the domain tells which link an event is about;
+-- like the other ids it has no foreign key so events outlive the domain
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS domain_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_domain_short ON audit_events (domain_id, short, created_at DESC);
@@ -7,6 +7,10 @@ class URLShortenerApp {
         this.currentResult = null;
         this.history = [];
         this.currentChart = null;
+        this.user = null;
+        this.workspaceId = localStorage.getItem('workspaceId') || '';
+        // prefix path when a reverse proxy serves the app under one
+        this.basePath = document.body.dataset.basePath || '';
         this.elements = this.cacheDOM();
         this.init();
     }
@@ -16,6 +20,16 @@ class URLShortenerApp {
             form: document.getElementById('shorten-form'),
             originalUrl: document.getElementById('original-url'),
             customShort: document.getElementById('custom-short'),
+            apiKey: document.getElementById('api-key'),
+            loginForm: document.getElementById('login-form'),
+            loginEmail: document.getElementById('login-email'),
+            loginPassword: document.getElementById('login-password'),
+            registerBtn: document.getElementById('register-btn'),
+            logoutBtn: document.getElementById('logout-btn'),
+            accountCard: document.getElementById('account-card'),
+            accountEmail: document.getElementById('account-email'),
+            accountLink: document.getElementById('account-link'),
+            workspaceSelect: document.getElementById('workspace-select'),
             submitBtn: document.getElementById('submit-btn'),
             resultCard: document.getElementById('result-card'),
             shortUrlDisplay: document.getElementById('short-url-display'),
@@ -28,10 +42,12 @@ class URLShortenerApp {
         };
     }
 
-    init() {
+    async init() {
+        this.elements.apiKey.value = localStorage.getItem('apiKey') || '';
         this.setupEventListeners();
-        this.loadHistory();
         this.animatePageLoad();
+        await this.loadSession();
+        this.loadHistory();
     }
 
     animatePageLoad() {
@@ -48,6 +64,19 @@ class URLShortenerApp {
 
     setupEventListeners() {
         this.elements.form.addEventListener('submit', e => this.handleSubmit(e));
+        this.elements.apiKey.addEventListener('change', () => {
+            localStorage.setItem('apiKey', this.elements.apiKey.value.trim());
+        });
+        this.elements.loginForm.addEventListener('submit', e => {
+            e.preventDefault();
+            this.authenticate('/auth/login');
+        });
+        this.elements.registerBtn.addEventListener('click', () => this.authenticate('/auth/register'));
+        this.elements.logoutBtn.addEventListener('click', () => this.logout());
+        this.elements.workspaceSelect.addEventListener('change', () => {
+            this.setWorkspace(this.elements.workspaceSelect.value);
+            this.loadHistory();
+        });
 
         this.elements.navLinks.forEach(link => {
             link.addEventListener('click', e => this.handleNavClick(e, link));
@@ -107,6 +136,97 @@ class URLShortenerApp {
         }
     }
 
+    // ============================================
+    // ACCOUNT
+    // ============================================
+
+    async loadSession() {
+        try {
+            const response = await fetch(this.basePath + '/auth/me');
+            await this.setUser(response.ok ? await response.json() : null);
+        } catch (e) {
+            this.setUser(null);
+        }
+    }
+
+    async authenticate(endpoint) {
+        if (!this.elements.loginForm.reportValidity()) return;
+
+        try {
+            const response = await fetch(this.basePath + endpoint, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    email: this.elements.loginEmail.value.trim(),
+                    password: this.elements.loginPassword.value
+                })
+            });
+            const data = await response.json();
+            if (!response.ok) {
+                throw new Error(data.error || 'Ошибка сервера');
+            }
+
+            this.elements.loginPassword.value = '';
+            await this.setUser(data);
+            this.loadHistory();
+            this.showToast(`Вы вошли как ${data.email}`, 'success');
+        } catch (error) {
+            this.showToast(error.message, 'error');
+        }
+    }
+
+    async logout() {
+        await fetch(this.basePath + '/auth/logout', { method: 'POST' }).catch(() => {});
+        await this.setUser(null);
+        this.loadHistory();
+        this.showToast('Вы вышли из аккаунта', 'info');
+    }
+
+    async setUser(user) {
+        this.user = user;
+        this.elements.loginForm.classList.toggle('hidden', !!user);
+        this.elements.accountCard.classList.toggle('hidden', !user);
+        this.elements.accountEmail.textContent = user ? user.email : '';
+        this.elements.accountLink.textContent = user ? user.email : 'Войти';
+
+        if (user) {
+            await this.loadWorkspaces();
+        } else {
+            this.setWorkspace('');
+            this.elements.workspaceSelect.innerHTML = '';
+        }
+    }
+
+    // loadWorkspaces fills the workspace picker, keeping the last choice if it is still available
+    async loadWorkspaces() {
+        try {
+            const response = await fetch(this.basePath + '/workspaces');
+            if (!response.ok) throw new Error(`Server error: ${response.status}`);
+
+            const data = await response.json();
+            const select = this.elements.workspaceSelect;
+            select.innerHTML = '';
+            data.workspaces.forEach(ws => {
+                const option = document.createElement('option');
+                option.value = ws.id;
+                option.textContent = `${ws.name} (${ws.role})`;
+                select.appendChild(option);
+            });
+
+            const ids = data.workspaces.map(ws => String(ws.id));
+            this.setWorkspace(ids.includes(this.workspaceId) ? this.workspaceId : (ids[0] || ''));
+            select.value = this.workspaceId;
+        } catch (e) {
+            console.warn('Could not load workspaces:', e);
+            this.setWorkspace('');
+        }
+    }
+
+    setWorkspace(id) {
+        this.workspaceId = id;
+        localStorage.setItem('workspaceId', id);
+    }
+
     // ============================================
     // URL SHORTENING
     // ============================================
@@ -145,18 +265,32 @@ class URLShortenerApp {
         }
     }
 
+    // authHeaders adds the API key and the selected workspace for endpoints behind auth
+    authHeaders(headers = {}) {
+        const key = this.elements.apiKey.value.trim();
+        if (key) headers = { ...headers, 'Authorization': `Bearer ${key}` };
+        return this.workspaceId ? { ...headers, 'X-Workspace-ID': this.workspaceId } : headers;
+    }
+
     async shortenUrl(url, customShort = '') {
         const requestBody = { url };
         if (customShort) requestBody.custom = customShort;
 
-        const response = await fetch('/shorten', {
+        const response = await fetch(this.basePath + '/shorten', {
             method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
+            headers: this.authHeaders({ 'Content-Type': 'application/json' }),
             body: JSON.stringify(requestBody)
         });
 
         const data = await response.json();
 
+        if (response.status === 401) {
+            throw new Error('Войдите или укажите API-ключ, чтобы создавать ссылки');
+        }
+        if (response.status === 403) {
+            throw new Error('В этом рабочем пространстве у вас нет прав на создание ссылок');
+        }
+
         if (!response.ok) {
             throw new Error(data.error || 'Ошибка сервера');
         }
@@ -164,7 +298,7 @@ class URLShortenerApp {
         return {
             short: data.short,
             original: url,
-            shortUrl: `${window.location.origin}/s/${data.short}`,
+            shortUrl: data.short_url,
             expires: data.expires,
             createdAt: Date.now()
         };
@@ -232,12 +366,16 @@ class URLShortenerApp {
     }
 
     async getAnalytics(shortCode) {
-        const response = await fetch(`/analytics/${shortCode}`);
+        const response = await fetch(`${this.basePath}/analytics/${shortCode}`, { headers: this.authHeaders() });
 
         if (response.status === 404) {
             throw new Error('URL not found');
         }
 
+        if (response.status === 401 || response.status === 403) {
+            throw new Error('Нужен API-ключ с доступом к аналитике');
+        }
+
         if (!response.ok) {
             const errorData = await response.json().catch(() => ({}));
             throw new Error(errorData.error || `Server error: ${response.status}`);
@@ -253,7 +391,8 @@ class URLShortenerApp {
             startDate.setDate(startDate.getDate() - 30);
 
             const response = await fetch(
-                `/analytics/${shortCode}/detailed?from=${startDate.toISOString().split('T')[0]}&to=${endDate.toISOString().split('T')[0]}`
+                `${this.basePath}/analytics/${shortCode}/detailed?from=${startDate.toISOString().split('T')[0]}&to=${endDate.toISOString().split('T')[0]}`,
+                { headers: this.authHeaders() }
             );
 
             return response.ok ? await response.json() : null;
@@ -372,7 +511,7 @@ class URLShortenerApp {
         const container = document.getElementById('recent-clicks');
 
         try {
-            const response = await fetch(`/analytics/${data.short}/recent-clicks`);
+            const response = await fetch(`${this.basePath}/analytics/${data.short}/recent-clicks`, { headers: this.authHeaders() });
             if (response.ok) {
                 const clicksData = await response.json();
                 if (clicksData.clicks?.length) {
@@ -405,6 +544,12 @@ class URLShortenerApp {
     // ============================================
 
     async loadHistory() {
+        if (this.user) {
+            await this.loadOwnedLinks();
+            this.renderHistory();
+            return;
+        }
+
         try {
             const savedHistory = localStorage.getItem('urlHistory');
             if (savedHistory) {
@@ -413,7 +558,7 @@ class URLShortenerApp {
 
             await Promise.all(this.history.map(async (item) => {
                 try {
-                    const res = await fetch(`/analytics/${item.short}`);
+                    const res = await fetch(`${this.basePath}/analytics/${item.short}`, { headers: this.authHeaders() });
                     if (res.ok) {
                         const data = await res.json();
                         item.visits = data.visit_count || 0;
@@ -430,6 +575,28 @@ class URLShortenerApp {
         this.renderHistory();
     }
 
+    // loadOwnedLinks shows the links of the selected workspace from the server instead of local history
+    async loadOwnedLinks() {
+        try {
+            const response = await fetch(this.basePath + '/links?limit=100', { headers: this.authHeaders() });
+            if (!response.ok) throw new Error(`Server error: ${response.status}`);
+
+            const data = await response.json();
+            // signed links open only through the token shown at creation
+            this.history = data.links.filter(link => link.short_url).map(link => ({
+                id: link.created_at,
+                short: link.short,
+                original: link.original,
+                shortUrl: link.short_url,
+                createdAt: link.created_at * 1000,
+                visits: link.visit_count
+            }));
+        } catch (e) {
+            console.warn('Could not load links:', e);
+            this.history = [];
+        }
+    }
+
     addToHistory(result) {
         this.history.unshift({
             ...result,